@@ -0,0 +1,139 @@
+package usqldrv_arango
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// AQL builds an AQL query and its bind vars, to be passed (via `AQL.Build`)
+// to `Conn.QueryContext` or `Conn.ExecContext` (see `Args`). The zero `AQL`
+// is ready to use, and all its methods append a statement and return `me`:
+//
+//	query, args, err := new(AQL).For("d", Collection("users")).Filter("d.age >=", minAge).Sort("d.name").Limit(0, 10).Return("d").Build()
+//
+// The `operands` of all methods are joined by spaces: `string`s are inserted
+// as-is (as raw AQL), `*AQL`s (see `AQL.Sub`) as parenthesized subqueries,
+// `Collection`s as automatically-allocated collection bind vars (`@@v1`),
+// and all other values as automatically-allocated bind vars (`@v1`), so to
+// bind (rather than insert) a `string` value, wrap it in `Val`.
+type AQL struct {
+	n     *int
+	stmts []string
+	args  []sql.NamedArg
+
+	graphVars string // see `AQL.ReturnGraphRow`
+	err       error  // see `AQL.Build`
+}
+
+type aqlVal struct{ value interface{} }
+
+// Val wraps `value` for `AQL` operands to always bind it, even if a `string`.
+func Val(value interface{}) interface{} { return aqlVal{value} }
+
+// Sub returns a new `AQL` to use as a subquery operand in `me`: it shares
+// `me`'s bind-var allocation, while its bind vars get added to `me`'s only
+// if and where it's used as an operand.
+func (me *AQL) Sub() *AQL {
+	if me.n == nil {
+		me.n = new(int)
+	}
+	return &AQL{n: me.n}
+}
+
+// For appends `FOR varNames IN in`, ie. `varNames` is usually just one name
+// (or eg. "v, e, p" for traversals), and `in` usually a `Collection`.
+func (me *AQL) For(varNames string, in ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"FOR " + varNames + " IN"}, in...)...)
+}
+
+// Filter appends `FILTER cond`.
+func (me *AQL) Filter(cond ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"FILTER"}, cond...)...)
+}
+
+// Sort appends `SORT by`, eg. `Sort("d.age DESC, d.name")`.
+func (me *AQL) Sort(by ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"SORT"}, by...)...)
+}
+
+// Limit appends `LIMIT offset, count` (or `LIMIT count` if `offset` is 0), both bound as bind vars.
+func (me *AQL) Limit(offset int64, count int64) *AQL {
+	if offset == 0 {
+		return me.Raw("LIMIT", count)
+	}
+	return me.Raw("LIMIT "+me.bind(offset)+",", count)
+}
+
+// Let appends `LET varName = value`.
+func (me *AQL) Let(varName string, value ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"LET " + varName + " ="}, value...)...)
+}
+
+// Collect appends `COLLECT spec`, eg. `Collect("city = d.city WITH COUNT INTO n")`.
+func (me *AQL) Collect(spec ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"COLLECT"}, spec...)...)
+}
+
+// Return appends `RETURN value`.
+func (me *AQL) Return(value ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"RETURN"}, value...)...)
+}
+
+// ReturnDistinct appends `RETURN DISTINCT value`.
+func (me *AQL) ReturnDistinct(value ...interface{}) *AQL {
+	return me.Raw(append([]interface{}{"RETURN DISTINCT"}, value...)...)
+}
+
+// Raw appends any other statement (eg. `INSERT`, `UPDATE`, `WINDOW`) made up of `operands`.
+func (me *AQL) Raw(operands ...interface{}) *AQL {
+	parts := make([]string, 0, len(operands))
+	for _, operand := range operands {
+		switch it := operand.(type) {
+		case string:
+			if it != "" {
+				parts = append(parts, it)
+			}
+		case *AQL:
+			parts, me.args = append(parts, "("+it.String()+")"), append(me.args, it.args...)
+			if me.err == nil {
+				me.err = it.err
+			}
+		case aqlVal:
+			parts = append(parts, me.bind(it.value))
+		default:
+			parts = append(parts, me.bind(it))
+		}
+	}
+	me.stmts = append(me.stmts, strings.Join(parts, " "))
+	return me
+}
+
+func (me *AQL) bind(value interface{}) (bindVar string) {
+	if me.n == nil {
+		me.n = new(int)
+	}
+	*me.n++
+	name := "v" + strconv.Itoa(*me.n)
+	if bindVar = "@" + name; isCollection(value) {
+		bindVar = "@" + bindVar
+	}
+	me.args = append(me.args, sql.Named(name, value))
+	return
+}
+
+func isCollection(value interface{}) (is bool) {
+	_, is = value.(Collection)
+	return
+}
+
+// String returns the query text as per `AQL.Build`.
+func (me *AQL) String() string { return strings.Join(me.stmts, " ") }
+
+// Build returns the `query` text and its `args`, both to be passed to
+// `Conn.QueryContext` or `Conn.ExecContext` (see `Args`), or the `err` of the
+// first misused method (incl. in subqueries), eg. an `AQL.ReturnGraphRow`
+// without a preceding graph `FOR`.
+func (me *AQL) Build() (query string, args []sql.NamedArg, err error) {
+	return me.String(), me.args, me.err
+}
@@ -0,0 +1,151 @@
+package usqldrv_arango
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAQLBuild(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		aql   func() *AQL
+		query string
+		args  []sql.NamedArg
+	}{
+		{
+			name: "for-filter-sort-limit-return",
+			aql: func() *AQL {
+				return new(AQL).For("d", Collection("users")).Filter("d.age >=", 21).Sort("d.name").Limit(0, 10).Return("d")
+			},
+			query: "FOR d IN @@v1 FILTER d.age >= @v2 SORT d.name LIMIT @v3 RETURN d",
+			args:  []sql.NamedArg{sql.Named("v1", Collection("users")), sql.Named("v2", 21), sql.Named("v3", int64(10))},
+		},
+		{
+			name:  "limit-with-offset",
+			aql:   func() *AQL { return new(AQL).For("d", Collection("users")).Limit(20, 10).Return("d._key") },
+			query: "FOR d IN @@v1 LIMIT @v2, @v3 RETURN d._key",
+			args:  []sql.NamedArg{sql.Named("v1", Collection("users")), sql.Named("v2", int64(20)), sql.Named("v3", int64(10))},
+		},
+		{
+			name: "strings-raw-unless-val",
+			aql: func() *AQL {
+				return new(AQL).For("d", Collection("users")).Filter("d.name ==", Val("x"), "? true : false").ReturnDistinct("d.city")
+			},
+			query: "FOR d IN @@v1 FILTER d.name == @v2 ? true : false RETURN DISTINCT d.city",
+			args:  []sql.NamedArg{sql.Named("v1", Collection("users")), sql.Named("v2", "x")},
+		},
+		{
+			name: "collect",
+			aql: func() *AQL {
+				return new(AQL).For("d", Collection("users")).Collect("city = d.city WITH COUNT INTO n").Return("{ city, n }")
+			},
+			query: "FOR d IN @@v1 COLLECT city = d.city WITH COUNT INTO n RETURN { city, n }",
+			args:  []sql.NamedArg{sql.Named("v1", Collection("users"))},
+		},
+		{
+			name: "let-subquery",
+			aql: func() *AQL {
+				q := new(AQL).For("u", Collection("users")).Filter("u.active ==", true)
+				orders := q.Sub().For("o", Collection("orders")).Filter("o.user == u._key AND o.total >", 100).Return("o")
+				return q.Let("orders", orders).Return("MERGE(u, { orders })")
+			},
+			query: "FOR u IN @@v1 FILTER u.active == @v2 LET orders = (FOR o IN @@v3 FILTER o.user == u._key AND o.total > @v4 RETURN o) RETURN MERGE(u, { orders })",
+			args:  []sql.NamedArg{sql.Named("v1", Collection("users")), sql.Named("v2", true), sql.Named("v3", Collection("orders")), sql.Named("v4", 100)},
+		},
+		{
+			name: "unused-subquery-binds-nothing",
+			aql: func() *AQL {
+				q := new(AQL)
+				_ = q.Sub().For("o", Collection("orders")).Return("o")
+				return q.For("d", Collection("users")).Return("d")
+			},
+			query: "FOR d IN @@v2 RETURN d",
+			args:  []sql.NamedArg{sql.Named("v2", Collection("users"))},
+		},
+		{
+			name: "raw",
+			aql: func() *AQL {
+				return new(AQL).Raw("INSERT", map[string]interface{}{"a": 1}, "IN", Collection("coll")).Return("NEW._key")
+			},
+			query: "INSERT @v1 IN @@v2 RETURN NEW._key",
+			args:  []sql.NamedArg{sql.Named("v1", map[string]interface{}{"a": 1}), sql.Named("v2", Collection("coll"))},
+		},
+		{
+			name: "traverse-prune",
+			aql: func() *AQL {
+				return new(AQL).Traverse(1, 3, GraphOutbound, "users/1", "social", "v.banned ==", true).Filter("v.age >", 30).ReturnGraphRow()
+			},
+			query: "FOR v, e, p IN 1..3 OUTBOUND @v1 GRAPH @v2 PRUNE v.banned == @v3 FILTER v.age > @v4 RETURN { vertex: v, edge: e, path: p }",
+			args:  []sql.NamedArg{sql.Named("v1", "users/1"), sql.Named("v2", "social"), sql.Named("v3", true), sql.Named("v4", 30)},
+		},
+		{
+			name:  "shortest-path",
+			aql:   func() *AQL { return new(AQL).ShortestPath(GraphAny, "users/1", "users/2", "social").ReturnGraphRow() },
+			query: "FOR v, e IN ANY SHORTEST_PATH @v1 TO @v2 GRAPH @v3 RETURN { vertex: v, edge: e }",
+			args:  []sql.NamedArg{sql.Named("v1", "users/1"), sql.Named("v2", "users/2"), sql.Named("v3", "social")},
+		},
+		{
+			name: "k-shortest-paths",
+			aql: func() *AQL {
+				return new(AQL).KShortestPaths(GraphInbound, "users/1", "users/2", "social").Limit(0, 3).ReturnGraphRow()
+			},
+			query: "FOR p IN INBOUND K_SHORTEST_PATHS @v1 TO @v2 GRAPH @v3 LIMIT @v4 RETURN { path: p }",
+			args:  []sql.NamedArg{sql.Named("v1", "users/1"), sql.Named("v2", "users/2"), sql.Named("v3", "social"), sql.Named("v4", int64(3))},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := test.aql().Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != test.query {
+				t.Errorf("query:\n got: %s\nwant: %s", query, test.query)
+			}
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("args:\n got: %v\nwant: %v", args, test.args)
+			}
+		})
+	}
+}
+
+func TestReturnGraphRowWithoutGraphFailsBuild(t *testing.T) {
+	q := new(AQL).For("u", Collection("users"))
+	friends := q.Sub().For("f", Collection("friends")).ReturnGraphRow()
+	query, _, err := q.Let("friends", friends).Return("u").Build()
+	if err == nil || !strings.Contains(err.Error(), "ReturnGraphRow") {
+		t.Fatalf("expected a ReturnGraphRow error, got: %v", err)
+	}
+	if strings.Contains(query, "RETURN {") || strings.Contains(query, "RETURN)") {
+		t.Fatalf("expected no RETURN appended by ReturnGraphRow, got: %s", query)
+	}
+}
+
+func TestGraphRowDecode(t *testing.T) {
+	type vertex struct {
+		Key  string `json:"_key"`
+		Name string `json:"name"`
+	}
+	rowcur := &arangoRowsCursor{}
+	row := GraphRowsInto(func() interface{} { return new(vertex) }, nil)(rowcur).(*GraphRow)
+	if err := json.Unmarshal([]byte(`{"vertex":{"_key":"2","name":"b"},"edge":{"_from":"users/1","_to":"users/2"},
+		"path":{"vertices":[{"_key":"1","name":"a"},{"_key":"2","name":"b"}],"edges":[{"_from":"users/1","_to":"users/2"}]}}`), row); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := row.Vertex.(*vertex); !ok || *v != (vertex{"2", "b"}) {
+		t.Fatalf("vertex: %#v", row.Vertex)
+	}
+	if e, ok := row.Edge.(*map[string]interface{}); !ok || (*e)["_to"] != "users/2" {
+		t.Fatalf("edge: %#v", row.Edge)
+	}
+	if len(row.Path.Vertices) != 2 || *row.Path.Vertices[0].(*vertex) != (vertex{"1", "a"}) || len(row.Path.Edges) != 1 {
+		t.Fatalf("path: %#v", row.Path)
+	}
+
+	var scanned GraphRow
+	if err := (&Doc{Into: &scanned}).Scan(row); err != nil || scanned.Vertex != row.Vertex {
+		t.Fatalf("scanned: %#v, %v", scanned, err)
+	}
+}
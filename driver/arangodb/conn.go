@@ -1,8 +1,14 @@
 package usqldrv_arango
 
 import (
+	"context"
 	sqldrv "database/sql/driver"
 	"errors"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
 
 	arango "github.com/arangodb/go-driver"
 )
@@ -11,15 +17,34 @@ import (
 //
 // Absent `error`s, its `sqldrv.QueryerContext` implementation returns
 // `RowsCursor`s that implement both `sqldrv.Rows` and `arango.Cursor`.
+//
+// Query args bind to AQL bind parameters either all by name (`sql.Named("foo", x)`
+// for `@foo`) or all by position (`@1`, `@2` etc. for the 1st, 2nd etc. arg).
+// Any JSON-marshalable arg values (incl. slices, maps and structs) are allowed,
+// and `Collection`-typed ones bind to collection bind parameters (`@@coll`).
 type Conn interface {
 	sqldrv.Conn
+	sqldrv.ConnBeginTx
+	sqldrv.ConnPrepareContext
 	sqldrv.ExecerContext
+	sqldrv.NamedValueChecker
+	sqldrv.Pinger
 	sqldrv.QueryerContext
+	sqldrv.SessionResetter
+	sqldrv.Validator
 	arango.Database
+
+	// Client returns the `arango.Client` shared by all `Conn`s of the same `Driver`,
+	// eg. for server-level operations in `Migration`s.
+	Client() arango.Client
+	// ServerInfo reports the version, storage engine and role of the server.
+	ServerInfo(context.Context) (ServerInfo, error)
 }
 
 type arangoConn struct {
-	drv *Driver
+	drv  *Driver
+	txID arango.TransactionID
+	bad  bool
 	arango.Database
 }
 
@@ -27,29 +52,84 @@ func (me *arangoConn) Client() arango.Client {
 	return me.drv.shared.Client
 }
 
-// Begin implements `sqldrv.Conn`, but always fails due to lack of support
-// by ArangoDB for the proper rollback/commit transactions paradigm.
+// do sends an HTTP request to the given ArangoDB `apiPath` (relative to this `Conn`'s
+// database, unless starting with a slash), for those APIs not (or not sufficiently)
+// covered by `arango.Database`.
+func (me *arangoConn) do(ctx context.Context, method string, apiPath string, body interface{}, okStatus int, resultField string, result interface{}) (resp arango.Response, err error) {
+	var req arango.Request
+	if !strings.HasPrefix(apiPath, "/") {
+		apiPath = path.Join("_db", url.PathEscape(me.Name()), apiPath)
+	}
+	conn := me.Client().Connection()
+	if req, err = conn.NewRequest(method, apiPath); err == nil && body != nil {
+		_, err = req.SetBody(body)
+	}
+	if err == nil {
+		if resp, err = conn.Do(ctx, req); err == nil {
+			if err = resp.CheckStatus(okStatus); err == nil && result != nil {
+				err = resp.ParseBody(resultField, result)
+			}
+		}
+	}
+	return
+}
+
+// Begin implements `sqldrv.Conn`, but always fails due to being deprecated in favour of `BeginTx`.
 //
-// The "transactions" that ArangoDB does support (that is, the server-side
-// execution of a client-provided JavaScript `function`), can be accessed
-// via this `Conn`'s `sqldrv.ExecerContext` implementation.
+// The JavaScript "transactions" that ArangoDB also supports (that is, the
+// server-side execution of a client-provided JavaScript `function`), can be
+// accessed via this `Conn`'s `sqldrv.ExecerContext` implementation.
 func (*arangoConn) Begin() (sqldrv.Tx, error) {
-	return nil, errors.New("bug: Begin should never be called since ArangoDB (and/or its currently-used underlying Go driver) does not support the proper rollback/commit transactions paradigm (use this `Conn`'s `ExecerContext` implementation for ArangoDB's own (so-called) 'transactions')")
+	return nil, errors.New("bug: `Begin` should never be called since this `database/sql/driver` also implements `ConnBeginTx`")
 }
 
 // Close implements `sqldrv.Conn` and is a no-op, given that ArangoDB talks via HTTP-APIs (no comment =)
 func (*arangoConn) Close() (err error) { return }
 
-// Prepare implements `sqldrv.Conn`, but always fails due to being deprecated in favour of `ExecerContext` / `QueryerContext`.
+// Prepare implements `sqldrv.Conn`, but always fails due to being deprecated in favour of `PrepareContext`.
 func (*arangoConn) Prepare(query string) (sqldrv.Stmt, error) {
-	return nil, errors.New("bug: `Prepare` should never be called since this `database/sql/driver` also implements `ExecerContext` and `QueryerContext`")
+	return nil, errors.New("bug: `Prepare` should never be called since this `database/sql/driver` also implements `ConnPrepareContext`")
+}
+
+// Collection can be used as a query arg value to bind a collection name to a
+// collection bind parameter, ie. `sql.Named("coll", Collection("users"))` for
+// `@@coll`, or positionally `Collection("users")` for `@@1`, `@@2` etc.
+type Collection string
+
+// CheckNamedValue implements `sqldrv.NamedValueChecker` so that, unlike with
+// `sqldrv.DefaultParameterConverter`, any JSON-marshalable value (incl. slices,
+// maps and structs) can be passed as a query arg. `sqldrv.Valuer`s are resolved,
+// with `nil` pointers to value-receiver `Valuer`s (eg. `(*sql.NullString)(nil)`) binding `nil`.
+func (*arangoConn) CheckNamedValue(arg *sqldrv.NamedValue) (err error) {
+	if valuer, _ := arg.Value.(sqldrv.Valuer); valuer != nil {
+		if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() && rv.Type().Elem().Implements(valuerType) {
+			arg.Value = nil
+		} else {
+			arg.Value, err = valuer.Value()
+		}
+	}
+	return
 }
 
-func (*arangoConn) bindVarsFrom(args []sqldrv.NamedValue) (bindVars map[string]interface{}) {
+var valuerType = reflect.TypeOf((*sqldrv.Valuer)(nil)).Elem()
+
+// bindVarsFrom binds named `args` by their `Name` (ie. `@name` in AQL) and
+// positional `args` by their `Ordinal` (ie. `@1`, `@2` etc. in AQL).
+func (*arangoConn) bindVarsFrom(args []sqldrv.NamedValue) (bindVars map[string]interface{}, err error) {
 	if len(args) > 0 {
 		bindVars = make(map[string]interface{}, len(args))
+		named := args[0].Name != ""
 		for i := range args {
-			bindVars[args[i].Name] = args[i].Value
+			name, value := args[i].Name, args[i].Value
+			if (name != "") != named {
+				return nil, errors.New("cannot mix named and positional (unnamed) args in the same query")
+			} else if !named {
+				name = strconv.Itoa(args[i].Ordinal)
+			}
+			if coll, ok := value.(Collection); ok {
+				name, value = "@"+name, string(coll)
+			}
+			bindVars[name] = value
 		}
 	}
 	return
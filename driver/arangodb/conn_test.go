@@ -0,0 +1,48 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"database/sql"
+	sqldrv "database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestCheckNamedValue(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		arg  interface{}
+		want interface{}
+	}{
+		{name: "nil-ptr-to-value-receiver-valuer", arg: (*sql.NullString)(nil), want: nil},
+		{name: "valid-valuer", arg: sql.NullString{String: "x", Valid: true}, want: "x"},
+		{name: "ptr-to-valuer", arg: &sql.NullInt64{Int64: 42, Valid: true}, want: int64(42)},
+		{name: "invalid-valuer", arg: sql.NullString{}, want: nil},
+		{name: "non-valuer", arg: 3.14, want: 3.14},
+	} {
+		arg := sqldrv.NamedValue{Ordinal: 1, Value: test.arg}
+		if err := (&arangoConn{}).CheckNamedValue(&arg); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if arg.Value != test.want {
+			t.Errorf("%s: got %#v, want %#v", test.name, arg.Value, test.want)
+		}
+	}
+}
+
+func TestQueryWithNilValuerPtrArg(t *testing.T) {
+	db := sql.OpenDB(newStandIn(t, false).connector(t))
+	defer db.Close()
+	rows, err := db.Query("FOR d IN coll FILTER d.name == @1 RETURN d", (*sql.NullString)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+}
+
+func TestPingMapsErrors(t *testing.T) {
+	srv := newStandIn(t, false) // 404s the version API
+	var e *Error
+	if err := srv.conn(t).Ping(context.Background()); !errors.As(err, &e) || e.HTTPCode != 404 {
+		t.Fatalf("expected an *Error, got: %#v", err)
+	}
+}
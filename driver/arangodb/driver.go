@@ -7,8 +7,10 @@ package usqldrv_arango
 
 import (
 	"context"
+	"database/sql"
 	sqldrv "database/sql/driver"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +18,14 @@ import (
 	arangohttp "github.com/arangodb/go-driver/http"
 )
 
+// DriverName is the name under which a zero `Driver` is `sql.Register`ed, so
+// that `sql.Open(DriverName, dsn)` works with any DSN accepted by `ParseDSN`.
+const DriverName = "arangodb"
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
 type none struct{}
 
 func (me none) UnmarshalJSON([]byte) error   { return nil }
@@ -34,9 +44,13 @@ type Driver struct {
 	// Endpoints, TLS, etc..
 	Config arangohttp.ConnectionConfig
 
+	// if any, idempotently ensured (see `DatabaseSpec.Ensure`) upon the first successful `Connect`
+	Schema []DatabaseSpec
+
 	shared struct {
 		arango.Client
 		sync.Mutex
+		schemaEnsured bool
 	}
 }
 
@@ -53,7 +67,19 @@ func (me *Driver) Open(name string) (sqldrv.Conn, error) {
 // OpenConnector implements `sqldrv.DriverContext`.
 // In the absence of `error`s, the `Connect` method of the returned
 // `sqldrv.Connector` always returns `usqldrv_arango.Conn`s.
+//
+// If `name` contains "://", it is a DSN for `ParseDSN` and the returned
+// `sqldrv.Connector` uses a new `Driver` configured from it (rather than `me`,
+// except for `me.Schema`), otherwise `name` is just the database name.
 func (me *Driver) OpenConnector(name string) (sqldrv.Connector, error) {
+	if strings.Contains(name, "://") {
+		drv, dbName, err := ParseDSN(name)
+		if err != nil {
+			return nil, err
+		}
+		drv.Schema = me.Schema
+		return connector{drv: drv, dbName: dbName}, nil
+	}
 	return connector{drv: me, dbName: name}, nil
 }
 
@@ -77,6 +103,18 @@ func (me *Driver) ensureClientConn() (err error) {
 	return
 }
 
+func (me *Driver) ensureSchema(ctx context.Context) (err error) {
+	me.shared.Lock()
+	if !me.shared.schemaEnsured {
+		for i := 0; err == nil && i < len(me.Schema); i++ {
+			err = me.Schema[i].Ensure(ctx, me.shared.Client)
+		}
+		me.shared.schemaEnsured = (err == nil)
+	}
+	me.shared.Unlock()
+	return mapErr(err)
+}
+
 // Driver implements `sqldrv.Connector`
 func (me connector) Driver() sqldrv.Driver {
 	return me.drv
@@ -85,9 +123,14 @@ func (me connector) Driver() sqldrv.Driver {
 // Connect implements `sqldrv.Connector`
 func (me connector) Connect(ctx context.Context) (conn sqldrv.Conn, err error) {
 	if err = me.drv.ensureClientConn(); err == nil {
+		err = me.drv.ensureSchema(ctx)
+	}
+	if err == nil {
 		var self arangoConn
 		if self.Database, err = me.drv.shared.Client.Database(ctx, me.dbName); err == nil {
 			self.drv, conn = me.drv, &self
+		} else {
+			err = mapErr(err)
 		}
 	}
 	return
@@ -0,0 +1,123 @@
+package usqldrv_arango
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// ParseDSN parses a connection string of the form
+//
+//	http[s]://[user[:pass]@]host1:port1[,host2:port2...][/dbname][?opt=val&...]
+//
+// into a new `Driver` and the database name to be passed to its `OpenConnector`
+// (defaulting to "_system" if none is given).
+//
+// Supported options:
+//
+//	auth=basic|jwt          (default: basic, only if user is given)
+//	sync=<time.Duration>    (SynchronizeEndpointsInterval)
+//	conn_limit=<int>        (Config.ConnLimit)
+//	tls_skip_verify=<bool>  (Config.TLSConfig.InsecureSkipVerify)
+//	tls_server_name=<host>  (Config.TLSConfig.ServerName)
+func ParseDSN(dsn string) (drv *Driver, dbName string, err error) {
+	pos := strings.Index(dsn, "://")
+	if pos <= 0 {
+		return nil, "", errors.New("DSN lacks a `http://` or `https://` scheme: " + dsn)
+	}
+	scheme, rest := dsn[:pos], dsn[pos+3:]
+	if scheme != "http" && scheme != "https" {
+		return nil, "", errors.New("DSN has unsupported scheme `" + scheme + "`, expected `http` or `https`")
+	}
+	var query string
+	if pos = strings.IndexByte(rest, '?'); pos >= 0 {
+		rest, query = rest[:pos], rest[pos+1:]
+	}
+	hosts := rest
+	if pos = strings.IndexByte(rest, '/'); pos >= 0 {
+		hosts, dbName = rest[:pos], strings.Trim(rest[pos+1:], "/")
+		if dbName, err = url.PathUnescape(dbName); err != nil {
+			return nil, "", err
+		}
+	}
+	if dbName == "" {
+		dbName = "_system"
+	}
+	var user, pass string
+	var hasuser bool
+	if pos = strings.LastIndexByte(hosts, '@'); pos >= 0 {
+		userinfo := hosts[:pos]
+		hosts, hasuser = hosts[pos+1:], true
+		if pos = strings.IndexByte(userinfo, ':'); pos >= 0 {
+			userinfo, pass = userinfo[:pos], userinfo[pos+1:]
+		}
+		if user, err = url.PathUnescape(userinfo); err == nil {
+			pass, err = url.PathUnescape(pass)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	drv = &Driver{}
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			drv.Config.Endpoints = append(drv.Config.Endpoints, scheme+"://"+host)
+		}
+	}
+	if len(drv.Config.Endpoints) == 0 {
+		return nil, "", errors.New("DSN specifies no endpoints: " + dsn)
+	}
+
+	var opts url.Values
+	if opts, err = url.ParseQuery(query); err != nil {
+		return nil, "", err
+	}
+	auth := "basic"
+	for name, vals := range opts {
+		val := vals[len(vals)-1]
+		switch name {
+		case "auth":
+			auth = val
+		case "sync":
+			drv.SynchronizeEndpointsInterval, err = time.ParseDuration(val)
+		case "conn_limit":
+			drv.Config.ConnLimit, err = strconv.Atoi(val)
+		case "tls_skip_verify":
+			var skip bool
+			if skip, err = strconv.ParseBool(val); err == nil {
+				drv.tlsConfig().InsecureSkipVerify = skip
+			}
+		case "tls_server_name":
+			drv.tlsConfig().ServerName = val
+		default:
+			err = errors.New("unknown DSN option `" + name + "`")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if hasuser {
+		switch auth {
+		case "basic":
+			drv.Authentication = arango.BasicAuthentication(user, pass)
+		case "jwt":
+			drv.Authentication = arango.JWTAuthentication(user, pass)
+		default:
+			return nil, "", errors.New("unsupported DSN `auth` option `" + auth + "`, expected `basic` or `jwt`")
+		}
+	}
+	return
+}
+
+func (me *Driver) tlsConfig() *tls.Config {
+	if me.Config.TLSConfig == nil {
+		me.Config.TLSConfig = &tls.Config{}
+	}
+	return me.Config.TLSConfig
+}
@@ -0,0 +1,55 @@
+package usqldrv_arango
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+func TestParseDSN(t *testing.T) {
+	for _, test := range []struct {
+		dsn       string
+		endpoints []string
+		dbName    string
+		auth      arango.Authentication
+		err       bool
+		check     func(*Driver) bool
+	}{
+		{dsn: "http://localhost:8529/mydb", endpoints: []string{"http://localhost:8529"}, dbName: "mydb"},
+		{dsn: "http://localhost:8529", endpoints: []string{"http://localhost:8529"}, dbName: "_system"},
+		{dsn: "https://h1:8529,h2:8529/my%20db/", endpoints: []string{"https://h1:8529", "https://h2:8529"}, dbName: "my db"},
+		{dsn: "http://us%40er:p%3Ass@h:8529/db", endpoints: []string{"http://h:8529"}, dbName: "db", auth: arango.BasicAuthentication("us@er", "p:ss")},
+		{dsn: "http://user:tok@h:8529/db?auth=jwt", endpoints: []string{"http://h:8529"}, dbName: "db", auth: arango.JWTAuthentication("user", "tok")},
+		{dsn: "http://h:8529/db?sync=1m&conn_limit=8&tls_skip_verify=true&tls_server_name=srv", endpoints: []string{"http://h:8529"}, dbName: "db",
+			check: func(drv *Driver) bool {
+				return drv.SynchronizeEndpointsInterval == time.Minute && drv.Config.ConnLimit == 8 &&
+					drv.Config.TLSConfig.InsecureSkipVerify && drv.Config.TLSConfig.ServerName == "srv"
+			}},
+		{dsn: "http://user@h:8529/db?auth=ldap", err: true},
+		{dsn: "http://h:8529/db?foo=bar", err: true},
+		{dsn: "http://h:8529/db?conn_limit=many", err: true},
+		{dsn: "http:///db", err: true},
+		{dsn: "http://user:pass@/db", err: true},
+		{dsn: "tcp://h:8529/db", err: true},
+		{dsn: "h:8529/db", err: true},
+	} {
+		drv, dbName, err := ParseDSN(test.dsn)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.dsn)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%s: %v", test.dsn, err)
+			continue
+		}
+		if dbName != test.dbName || !reflect.DeepEqual(drv.Config.Endpoints, test.endpoints) || !reflect.DeepEqual(drv.Authentication, test.auth) {
+			t.Errorf("%s: got %q %v %#v", test.dsn, dbName, drv.Config.Endpoints, drv.Authentication)
+		}
+		if test.check != nil && !test.check(drv) {
+			t.Errorf("%s: unexpected options: %#v", test.dsn, drv)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package usqldrv_arango
+
+import (
+	"context"
+	sqldrv "database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// Error is the `error` returned by our `Conn`s (and their `RowsCursor`s and
+// transactions) whenever ArangoDB refused a request. Other failures are either
+// returned unchanged or, if the request couldn't even be sent (such that it is
+// safe for `database/sql` to retry it on another connection), as `sqldrv.ErrBadConn`.
+type Error struct {
+	// the HTTP status code, eg. 404
+	HTTPCode int
+	// the ArangoDB error number, eg. 1202 for "document not found"
+	ErrorNum int
+	Message  string
+
+	cause error
+}
+
+// Error implements the `error` interface.
+func (me *Error) Error() string {
+	return "ArangoDB error " + strconv.Itoa(me.ErrorNum) + " (HTTP " + strconv.Itoa(me.HTTPCode) + "): " + me.Message
+}
+
+// Unwrap returns the underlying `arango.ArangoError`.
+func (me *Error) Unwrap() error { return me.cause }
+
+// IsNotFound tells whether `err` is an `Error` for a missing document, collection, view or database.
+func IsNotFound(err error) bool {
+	return isErrorWith(err, 404, 1202, 1203, 1228)
+}
+
+// IsUniqueConstraintViolation tells whether `err` is an `Error` for a violated unique index (or `_key`) constraint.
+func IsUniqueConstraintViolation(err error) bool {
+	return isErrorWith(err, 0, 1210)
+}
+
+// IsWriteWriteConflict tells whether `err` is an `Error` for conflicting concurrent writes to the same document.
+func IsWriteWriteConflict(err error) bool {
+	return isErrorWith(err, 0, 1200)
+}
+
+// IsQueryParseError tells whether `err` is an `Error` for AQL syntax errors.
+func IsQueryParseError(err error) bool {
+	return isErrorWith(err, 0, 1501)
+}
+
+// IsTimeout tells whether `err` is an `Error` for a server-side timeout (incl.
+// lock timeouts and queries killed for exceeding `QueryOptions.MaxRuntime`),
+// or the result of an expired `context.Context` deadline.
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || arango.IsTimeout(err) || isErrorWith(err, 408, 18, 1457, 1500) || isErrorWith(err, 504)
+}
+
+func isErrorWith(err error, httpCode int, errorNums ...int) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		if httpCode != 0 && e.HTTPCode == httpCode {
+			return true
+		}
+		for _, errnum := range errorNums {
+			if e.ErrorNum == errnum {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errFrom is like `mapErr`, but also marks this `Conn` as no longer valid for reuse on endpoint failures.
+func (me *arangoConn) errFrom(err error) error {
+	me.markBadOn(err)
+	return mapErr(err)
+}
+
+// mapErr maps `err` (as returned by the underlying ArangoDB Go driver) to an
+// `*Error` or `sqldrv.ErrBadConn` as described for `Error`.
+func mapErr(err error) error {
+	if err == nil || err == sqldrv.ErrBadConn {
+		return err
+	} else if e, ok := err.(*Error); ok {
+		return e
+	}
+	if ae, ok := arango.AsArangoError(err); ok {
+		return &Error{HTTPCode: ae.Code, ErrorNum: ae.ErrorNum, Message: ae.ErrorMessage, cause: err}
+	}
+	var operr *net.OpError
+	if errors.As(arango.Cause(err), &operr) && operr.Op == "dial" && !arango.IsResponse(err) {
+		return sqldrv.ErrBadConn
+	}
+	return err
+}
@@ -2,21 +2,70 @@ package usqldrv_arango
 
 import (
 	"context"
+	"database/sql"
 	sqldrv "database/sql/driver"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	arango "github.com/arangodb/go-driver"
 )
 
-type execResult struct {
+// ExecResult is the `sqldrv.Result` returned by our `Conn`s' `sqldrv.ExecerContext`
+// implementation. As `database/sql` hides it behind its own `sql.Result`, pass
+// your own to be filled via `ExecResultInto`.
+type ExecResult struct {
+	// the metadata of all documents `RETURN`ed by the query, in order
+	// (eg. via `WriteReturnMeta` or `InsertBatch`'s `returnDocKeys`)
+	Docs []arango.DocumentMeta
+	// as per `QueryStats`
+	WritesExecuted int64
+	// as per `QueryStats`
+	WritesIgnored int64
+
 	numRows int64
-	last    string
 }
 
-func (me execResult) RowsAffected() (int64, error) { return me.numRows, nil }
-func (me execResult) LastInsertId() (int64, error) { return strconv.ParseInt(me.last, 10, 64) }
+// ErrLastInsertIdNotNumeric is returned by `ExecResult.LastInsertId` if the
+// last `RETURN`ed document's `_key` is not an integer (see `ExecResult.Docs`).
+var ErrLastInsertIdNotNumeric = errors.New("LastInsertId: last written document's _key is not numeric")
+
+// ctxKey identifies our `context.Context` values, so that those from `Transact`,
+// `QueryWith` etc. are found no matter how (or in which order) they're combined.
+type ctxKey int
+
+const (
+	ctxKeyExecResultInto ctxKey = iota
+	ctxKeyTransact
+	ctxKeyQuery
+)
+
+// ExecResultInto returns a `context.Context` from `ctx` that can be passed to
+// `Conn.ExecContext` to have its `ExecResult` also copied into `into`.
+func ExecResultInto(ctx context.Context, into *ExecResult) context.Context {
+	return context.WithValue(ctx, ctxKeyExecResultInto, into)
+}
+
+// RowsAffected implements `sqldrv.Result` and returns `WritesExecuted` if
+// non-zero, else the number of documents `RETURN`ed (or -1 for transactions).
+func (me *ExecResult) RowsAffected() (int64, error) { return me.numRows, nil }
+
+// LastInsertId implements `sqldrv.Result` and returns the last `RETURN`ed
+// document's `_key` if numeric, else `ErrLastInsertIdNotNumeric`.
+func (me *ExecResult) LastInsertId() (int64, error) {
+	if len(me.Docs) == 0 {
+		return 0, errors.New("LastInsertId: no document metadata was RETURNed by the query")
+	}
+	key := me.Docs[len(me.Docs)-1].Key
+	id, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrLastInsertIdNotNumeric, key)
+	}
+	return id, nil
+}
 
 type transactCtx struct {
 	context.Context
@@ -24,68 +73,260 @@ type transactCtx struct {
 	OnSuccess func(interface{})
 }
 
-// Insert constructs a `query` (to insert `doc` into `coll`) that can be passed to `Conn.ExecContext`.
-func Insert(coll string, returnDocKeyOnly bool, returnDocFully bool, doc interface{}) (query string, isTransact bool, err error) {
-	var data []byte
-	if data, err = json.Marshal(doc); err == nil {
-		if query = "INSERT " + string(data) + " IN " + coll; returnDocKeyOnly || returnDocFully {
-			if query += " LET d = NEW RETURN"; returnDocKeyOnly {
-				query += " { _key: d._key }"
-			} else if returnDocFully {
-				query += " d"
-			}
+// Value implements `context.Context`.
+func (me *transactCtx) Value(key interface{}) interface{} {
+	if key == ctxKeyTransact {
+		return me
+	}
+	return me.Context.Value(key)
+}
+
+// Insert constructs a `query` (to insert `doc` into `coll`) and its `args`,
+// both to be passed to `Conn.ExecContext` or `Conn.QueryContext` (see `Args`).
+func Insert(coll string, returnDocKeyOnly bool, returnDocFully bool, options *InsertOptions, doc interface{}) (query string, args []sql.NamedArg) {
+	if query = "INSERT @doc IN @@coll" + options.aql(); returnDocKeyOnly {
+		query += " RETURN { _key: NEW._key }"
+	} else if returnDocFully {
+		query += " RETURN NEW"
+	}
+	args = []sql.NamedArg{sql.Named("doc", doc), sql.Named("coll", Collection(coll))}
+	return
+}
+
+// InsertOverwriteMode denotes how `Insert` and `InsertBatch` queries handle
+// documents whose `_key` already exists in the collection.
+type InsertOverwriteMode string
+
+const (
+	// fail with a unique constraint violation (ArangoDB's default)
+	InsertOverwriteConflict InsertOverwriteMode = "conflict"
+	// keep the existing document, ignoring the new one
+	InsertOverwriteIgnore InsertOverwriteMode = "ignore"
+	// replace the existing document with the new one
+	InsertOverwriteReplace InsertOverwriteMode = "replace"
+	// merge the new document into the existing one (see `InsertOptions.DiscardNulls`)
+	InsertOverwriteUpdate InsertOverwriteMode = "update"
+)
+
+// InsertOptions are the AQL `OPTIONS` for `Insert` and `InsertBatch` queries.
+// The zero value implies ArangoDB's defaults.
+type InsertOptions struct {
+	// overwriteMode (omitted if empty)
+	OverwriteMode InsertOverwriteMode
+	// keepNull: false (only for `InsertOverwriteUpdate`)
+	DiscardNulls bool
+	IgnoreErrors bool
+	WaitForSync  bool
+}
+
+func (me *InsertOptions) aql() string {
+	var opts []string
+	if me != nil {
+		if me.OverwriteMode != "" {
+			opts = append(opts, "overwriteMode: "+strconv.Quote(string(me.OverwriteMode)))
+		}
+		if me.DiscardNulls && me.OverwriteMode == InsertOverwriteUpdate {
+			opts = append(opts, "keepNull: false")
+		}
+		if me.IgnoreErrors {
+			opts = append(opts, "ignoreErrors: true")
+		}
+		if me.WaitForSync {
+			opts = append(opts, "waitForSync: true")
 		}
 	}
+	return aqlOptions(opts)
+}
+
+func aqlOptions(opts []string) string {
+	if len(opts) == 0 {
+		return ""
+	}
+	return " OPTIONS { " + strings.Join(opts, ", ") + " }"
+}
+
+// InsertBatch constructs a `query` (to insert all of the `docs` (a slice) into
+// `coll` in a single round-trip) and its `args`, both to be passed to
+// `Conn.ExecContext` (see `Args`), where `RowsAffected` then reports the
+// number of documents inserted.
+func InsertBatch(coll string, returnDocKeys bool, options *InsertOptions, docs interface{}) (query string, args []sql.NamedArg) {
+	if query = "FOR d IN @docs INSERT d IN @@coll" + options.aql(); returnDocKeys {
+		query += " RETURN { _key: NEW._key }"
+	}
+	args = []sql.NamedArg{sql.Named("docs", docs), sql.Named("coll", Collection(coll))}
+	return
+}
+
+// Args converts `named` for passing as the variadic `args` of `sql.DB.ExecContext` / `sql.DB.QueryContext` etc.
+func Args(named []sql.NamedArg) (args []interface{}) {
+	args = make([]interface{}, len(named))
+	for i := range named {
+		args[i] = named[i]
+	}
 	return
 }
 
 // Transact returns a `context.Context` from `ctx` that can be passed to
-// `Conn.ExecContext` to signify that the query is an ArangoDB "transaction"
+// `Conn.ExecContext` (or `Conn.QueryContext`) to signify that the query is an ArangoDB "transaction"
 // JavaScript function to be run on the server using `transactionOptions`. If
 // `onSucceeded` is given, it is called with either `nil` or the JS func's result.
+//
+// Any `args` passed to `Conn.ExecContext` along with it become the JS func's
+// `params`: an object if they're named, or an array if they're positional.
 func Transact(ctx context.Context, transactionOptions *arango.TransactionOptions, onSucceeded func(result interface{})) context.Context {
 	return &transactCtx{Context: ctx, Options: transactionOptions, OnSuccess: onSucceeded}
 }
 
-// ExecContext implements `sqldrv.ExecerContext`
+// ExecContext implements `sqldrv.ExecerContext` and if no `err`, `r` will always be an `*ExecResult`.
 func (me *arangoConn) ExecContext(ctx context.Context, query string, args []sqldrv.NamedValue) (r sqldrv.Result, err error) {
 	var wastransact bool
-	if wastransact, err = me.transactMaybe(ctx, query); err == nil {
-		res := execResult{numRows: -1}
+	if wastransact, _, err = me.transactMaybe(ctx, query, args); err == nil {
+		res := &ExecResult{numRows: -1}
 		if !wastransact {
 			var rows sqldrv.Rows
 			var nope none
 			if rows, err = me.QueryContext(ctx, query, args); err == nil {
-				rowcur := rows.(*arangoRowsCursor)
-				res.numRows, rowcur.onReadDocIntoNewPtr = 0, func(RowsCursor) interface{} { return &nope }
+				rowcur, _ := rows.(*arangoRowsCursor)
+				if batchcur, _ := rows.(*batchRowsCursor); batchcur != nil {
+					rowcur = batchcur.arangoRowsCursor
+				}
+				res.numRows, rowcur.onReadDocIntoNewPtr, rowcur.flat = 0, func(RowsCursor) interface{} { return &nope }, nil
 				cells := make([]sqldrv.Value, len(rowcur.Columns()))
 				for err == nil && !rowcur.eof {
 					if err = rowcur.Next(cells); err == nil {
-						res.numRows, res.last = res.numRows+1, cells[1].(arango.DocumentMeta).Key
+						res.numRows++
+						if meta := cells[1].(arango.DocumentMeta); meta.Key != "" || meta.ID != "" {
+							res.Docs = append(res.Docs, meta)
+						}
 					} else if err == io.EOF {
 						err = nil
 						break
 					}
 				}
-				if res.numRows == 0 {
-					if querystats := rowcur.Cursor.Statistics(); querystats != nil {
-						res.numRows = querystats.WritesExecuted()
-					}
+				stats := rowcur.QueryStats()
+				if res.WritesExecuted, res.WritesIgnored = stats.WritesExecuted, stats.WritesIgnored; stats.WritesExecuted > 0 || res.numRows == 0 {
+					res.numRows = stats.WritesExecuted
+				}
+				if errclose := rowcur.Close(); err == nil {
+					err = errclose
 				}
 			}
 		}
-		r = res
+		if r = res; err == nil {
+			if into, _ := ctx.Value(ctxKeyExecResultInto).(*ExecResult); into != nil {
+				*into = *res
+			}
+		}
 	}
+	err = me.errFrom(err)
 	return
 }
 
-func (me *arangoConn) transactMaybe(ctx context.Context, query string) (didAttempt bool, err error) {
-	tctx, _ := ctx.(*transactCtx)
+func (me *arangoConn) transactMaybe(ctx context.Context, query string, args []sqldrv.NamedValue) (didAttempt bool, result interface{}, err error) {
+	tctx, _ := ctx.Value(ctxKeyTransact).(*transactCtx)
 	if didAttempt = tctx != nil; didAttempt {
-		var result interface{}
-		if result, err = me.Transaction(tctx.Context, query, tctx.Options); err == nil && tctx.OnSuccess != nil {
+		if result, err = me.transact(tctx.Context, query, tctx.Options, args); err == nil && tctx.OnSuccess != nil {
 			tctx.OnSuccess(result)
 		}
 	}
 	return
 }
+
+type transactRequest struct {
+	MaxTransactionSize      int         `json:"maxTransactionSize"`
+	LockTimeout             *int        `json:"lockTimeout,omitempty"`
+	WaitForSync             bool        `json:"waitForSync"`
+	IntermediateCommitCount *int        `json:"intermediateCommitCount,omitempty"`
+	Params                  interface{} `json:"params"`
+	IntermediateCommitSize  *int        `json:"intermediateCommitSize,omitempty"`
+	Action                  string      `json:"action"`
+	Collections             struct {
+		Read      []string `json:"read,omitempty"`
+		Write     []string `json:"write,omitempty"`
+		Exclusive []string `json:"exclusive,omitempty"`
+	} `json:"collections"`
+}
+
+// transact runs the JavaScript `action` via `arango.Database.Transaction`, except
+// for named `args`: these become a `params` object, which the `[]interface{}`
+// of `arango.TransactionOptions.Params` cannot express, so they're sent directly.
+func (me *arangoConn) transact(ctx context.Context, action string, options *arango.TransactionOptions, args []sqldrv.NamedValue) (result interface{}, err error) {
+	if len(args) == 0 {
+		return me.Transaction(ctx, action, options)
+	}
+	var opts arango.TransactionOptions
+	if options != nil {
+		opts = *options
+	}
+	if len(opts.Params) > 0 {
+		return nil, errors.New("cannot pass both `args` and `TransactionOptions.Params` to a JavaScript transaction")
+	}
+	named, params := args[0].Name != "", map[string]interface{}{}
+	for i := range args {
+		value := args[i].Value
+		if coll, ok := value.(Collection); ok {
+			value = string(coll)
+		}
+		if (args[i].Name != "") != named {
+			return nil, errors.New("cannot mix named and positional (unnamed) args in the same query")
+		} else if named {
+			params[args[i].Name] = value
+		} else {
+			opts.Params = append(opts.Params, value)
+		}
+	}
+	if !named {
+		return me.Transaction(ctx, action, &opts)
+	}
+
+	req := transactRequest{Action: action, Params: params, MaxTransactionSize: opts.MaxTransactionSize, LockTimeout: opts.LockTimeout,
+		WaitForSync: opts.WaitForSync, IntermediateCommitCount: opts.IntermediateCommitCount, IntermediateCommitSize: opts.IntermediateCommitSize}
+	req.Collections.Read, req.Collections.Write, req.Collections.Exclusive = opts.ReadCollections, opts.WriteCollections, opts.ExclusiveCollections
+	_, err = me.do(ctx, "POST", "_api/transaction", req, 200, "result", &result)
+	return
+}
+
+// transactCursor is the `arango.Cursor` over a JavaScript transaction's result.
+type transactCursor struct {
+	docs []json.RawMessage
+	idx  int
+}
+
+func newTransactCursor(result interface{}) (cur *transactCursor, err error) {
+	cur = &transactCursor{}
+	if result != nil {
+		var data []byte
+		if data, err = json.Marshal(result); err == nil {
+			if _, isarr := result.([]interface{}); isarr {
+				err = json.Unmarshal(data, &cur.docs)
+			} else {
+				cur.docs = []json.RawMessage{data}
+			}
+		}
+	}
+	return
+}
+
+func (me *transactCursor) Close() error                       { return nil }
+func (me *transactCursor) Count() int64                       { return int64(len(me.docs)) }
+func (me *transactCursor) Extra() arango.QueryExtra           { return nil }
+func (me *transactCursor) HasMore() bool                      { return me.idx < len(me.docs) }
+func (me *transactCursor) Statistics() arango.QueryStatistics { return nil }
+
+func (me *transactCursor) ReadDocument(_ context.Context, result interface{}) (meta arango.DocumentMeta, err error) {
+	if !me.HasMore() {
+		return meta, arango.NoMoreDocumentsError{}
+	}
+	doc := me.docs[me.idx]
+	me.idx++
+	_ = json.Unmarshal(doc, &meta) // fails for non-object docs, which have no meta anyway
+	err = json.Unmarshal(doc, result)
+	return
+}
+
+func (me *transactCursor) RetryReadDocument(ctx context.Context, result interface{}) (arango.DocumentMeta, error) {
+	if me.idx > 0 {
+		me.idx--
+	}
+	return me.ReadDocument(ctx, result)
+}
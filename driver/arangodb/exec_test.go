@@ -0,0 +1,43 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestExecResultIntoCombinesWithOtherCtxs(t *testing.T) {
+	srv := newStandIn(t, false)
+	query, args := Insert("users", true, false, nil, map[string]interface{}{"name": "x"})
+	srv.batches = map[string][]map[string]interface{}{
+		query: {{"result": []interface{}{map[string]interface{}{"_key": "7"}}, "extra": map[string]interface{}{"stats": map[string]interface{}{"writesExecuted": 1}}}},
+	}
+	db := sql.OpenDB(srv.connector(t))
+	defer db.Close()
+
+	res := ExecResult{numRows: 123}
+	if _, err := db.ExecContext(ExecResultInto(Transact(context.Background(), nil, nil), &res), "function () {}"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != -1 || len(srv.transactions) != 1 || len(srv.queries) != 0 {
+		t.Fatalf("expected a JavaScript transaction, got rows affected %d, transactions %q, queries %q", n, srv.transactions, srv.queries)
+	}
+
+	res = ExecResult{}
+	if _, err := db.ExecContext(QueryWith(ExecResultInto(context.Background(), &res), &QueryOptions{Stream: true}), query, Args(args)...); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := res.LastInsertId(); id != 7 || res.WritesExecuted != 1 {
+		t.Fatalf("expected the ExecResult filled, got: %#v", res)
+	}
+
+	rows, err := srv.conn(t).QueryContext(ExecResultInto(QueryWith(context.Background(), &QueryOptions{Flattened: true}), &res), "FOR d IN coll RETURN d", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if cols := rows.Columns(); !reflect.DeepEqual(cols, []string{"_key"}) {
+		t.Fatalf("expected QueryWith's flattened columns, got: %q", cols)
+	}
+}
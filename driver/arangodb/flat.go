@@ -0,0 +1,117 @@
+package usqldrv_arango
+
+import (
+	"bytes"
+	"context"
+	sqldrv "database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// QueryFlattened returns a `context.Context` that can be passed to `Conn.QueryContext`
+// to have the resulting `RowsCursor` expose each top-level attribute of the result
+// documents as its own column (instead of the `ColNameDoc` and `ColNameMeta` ones).
+//
+// If no `columns` are given, they are taken (in order) from the first result
+// document. Attributes missing in a document are `nil` cells, numbers are
+// `int64` or `float64` cells, and nested objects or arrays are `[]byte` cells
+// of raw JSON, so that standard `sql.Rows.Scan` destinations can be used.
+func QueryFlattened(ctx context.Context, wantCountInRowsCursor bool, columns ...string) context.Context {
+	return QueryWith(ctx, &QueryOptions{Count: wantCountInRowsCursor, Flattened: true, FlattenedColumns: columns})
+}
+
+type flatRows struct {
+	cols   []string
+	peeked map[string]json.RawMessage
+	err    error
+}
+
+func (me *arangoRowsCursor) flatColumns() []string {
+	if me.flat.cols == nil {
+		var keys []string
+		if me.flat.peeked, keys, me.flat.err = me.readFlat(); me.flat.err == io.EOF {
+			me.flat.err = nil
+		}
+		me.flat.cols = append(make([]string, 0, len(keys)), keys...)
+	}
+	return me.flat.cols
+}
+
+func (me *arangoRowsCursor) nextFlat(cells []sqldrv.Value) (err error) {
+	me.flatColumns()
+	doc := me.flat.peeked
+	if me.flat.peeked, err, me.flat.err = nil, me.flat.err, nil; err == nil && doc == nil {
+		doc, _, err = me.readFlat()
+	}
+	if err == nil {
+		for i, col := range me.flat.cols {
+			if cells[i], err = flatValue(doc[col]); err != nil {
+				break
+			}
+		}
+	} else if err != io.EOF {
+		err = me.conn.errFrom(err)
+	}
+	return
+}
+
+func (me *arangoRowsCursor) readFlat() (doc map[string]json.RawMessage, keys []string, err error) {
+	if !me.eof {
+		me.eof = !me.HasMore()
+	}
+	if me.eof {
+		me.stopWatchingCancel()
+		return nil, nil, io.EOF
+	}
+	var raw json.RawMessage
+	_, err = me.ReadDocument(me.ctx, &raw)
+	if me.onRawResponse(); err != nil {
+		if me.eof = arango.IsNoMoreDocuments(err); me.eof {
+			me.stopWatchingCancel()
+			err = io.EOF
+		}
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, _ := dec.Token(); tok != json.Delim('{') {
+		return nil, nil, errors.New("flattened query results must be objects, got: " + string(raw))
+	}
+	doc = map[string]json.RawMessage{}
+	for err == nil && dec.More() {
+		var tok json.Token
+		if tok, err = dec.Token(); err == nil {
+			key, _ := tok.(string)
+			var val json.RawMessage
+			if err = dec.Decode(&val); err == nil {
+				if _, exists := doc[key]; !exists {
+					keys = append(keys, key)
+				}
+				doc[key] = val
+			}
+		}
+	}
+	return
+}
+
+func flatValue(raw json.RawMessage) (value sqldrv.Value, err error) {
+	if len(raw) > 0 {
+		switch raw[0] {
+		case '{', '[':
+			value = []byte(raw)
+		default:
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			if err = dec.Decode(&value); err == nil {
+				if num, ok := value.(json.Number); ok {
+					if value, err = num.Int64(); err != nil {
+						value, err = num.Float64()
+					}
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package usqldrv_arango
+
+import (
+	"context"
+	sqldrv "database/sql/driver"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryFlattened(t *testing.T) {
+	docs := func(docs ...interface{}) map[string]interface{} { return map[string]interface{}{"result": docs} }
+	type obj = map[string]interface{}
+	for _, test := range []struct {
+		name    string
+		columns []string
+		batches []map[string]interface{}
+		cols    []string
+		rows    [][]sqldrv.Value
+		err     string
+	}{
+		{
+			name:    "columns-from-first-doc",
+			batches: []map[string]interface{}{docs(json.RawMessage(`{"b":1,"a":"x","c":{"n":1}}`)), docs(obj{"a": "y", "b": 2.5, "d": true})},
+			cols:    []string{"b", "a", "c"},
+			rows:    [][]sqldrv.Value{{int64(1), "x", []byte(`{"n":1}`)}, {2.5, "y", nil}},
+		},
+		{
+			name:    "explicit-columns",
+			columns: []string{"a", "z"},
+			batches: []map[string]interface{}{docs(obj{"b": 1, "a": "x"}, obj{"z": []interface{}{1, 2}})},
+			cols:    []string{"a", "z"},
+			rows:    [][]sqldrv.Value{{"x", nil}, {nil, []byte(`[1,2]`)}},
+		},
+		{
+			name:    "non-object-result",
+			batches: []map[string]interface{}{docs(42)},
+			cols:    []string{},
+			err:     "must be objects",
+		},
+		{
+			name:    "empty-result",
+			batches: []map[string]interface{}{docs()},
+			cols:    []string{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			srv := newStandIn(t, false)
+			srv.batches = map[string][]map[string]interface{}{"FOR d IN coll RETURN d": test.batches}
+			rows, err := srv.conn(t).QueryContext(QueryFlattened(context.Background(), false, test.columns...), "FOR d IN coll RETURN d", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			if cols := rows.Columns(); !reflect.DeepEqual(cols, test.cols) {
+				t.Fatalf("columns:\n got: %q\nwant: %q", cols, test.cols)
+			}
+			var got [][]sqldrv.Value
+			for {
+				cells := make([]sqldrv.Value, len(test.cols))
+				if err = rows.Next(cells); err != nil {
+					break
+				}
+				got = append(got, cells)
+			}
+			if test.err != "" {
+				if err == nil || err == io.EOF || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got: %v", test.err, err)
+				}
+			} else if err != io.EOF {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(got, test.rows) {
+				t.Fatalf("rows:\n got: %#v\nwant: %#v", got, test.rows)
+			}
+		})
+	}
+}
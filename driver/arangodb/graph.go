@@ -0,0 +1,127 @@
+package usqldrv_arango
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// GraphDirection denotes which edges `AQL.Traverse`, `AQL.ShortestPath` and `AQL.KShortestPaths` follow.
+type GraphDirection string
+
+const (
+	// follow edges from `_from` to `_to`
+	GraphOutbound GraphDirection = "OUTBOUND"
+	// follow edges from `_to` to `_from`
+	GraphInbound GraphDirection = "INBOUND"
+	// follow edges in both directions
+	GraphAny GraphDirection = "ANY"
+)
+
+// Traverse appends `FOR v, e, p IN min..max direction @start GRAPH @graph`
+// (and `PRUNE prune` if any), with `start` being a vertex document or `_id`.
+func (me *AQL) Traverse(min int, max int, direction GraphDirection, start interface{}, graph string, prune ...interface{}) *AQL {
+	me.graphVars = "{ vertex: v, edge: e, path: p }"
+	operands := []interface{}{"FOR v, e, p IN " + strconv.Itoa(min) + ".." + strconv.Itoa(max) + " " + string(direction), Val(start), "GRAPH", Val(graph)}
+	if len(prune) > 0 {
+		operands = append(append(operands, "PRUNE"), prune...)
+	}
+	return me.Raw(operands...)
+}
+
+// ShortestPath appends `FOR v, e IN direction SHORTEST_PATH @start TO @target GRAPH @graph`.
+func (me *AQL) ShortestPath(direction GraphDirection, start interface{}, target interface{}, graph string) *AQL {
+	me.graphVars = "{ vertex: v, edge: e }"
+	return me.Raw("FOR v, e IN "+string(direction)+" SHORTEST_PATH", Val(start), "TO", Val(target), "GRAPH", Val(graph))
+}
+
+// KShortestPaths appends `FOR p IN direction K_SHORTEST_PATHS @start TO @target GRAPH @graph`,
+// usually to be followed by a `Limit` on the number of paths.
+func (me *AQL) KShortestPaths(direction GraphDirection, start interface{}, target interface{}, graph string) *AQL {
+	me.graphVars = "{ path: p }"
+	return me.Raw("FOR p IN "+string(direction)+" K_SHORTEST_PATHS", Val(start), "TO", Val(target), "GRAPH", Val(graph))
+}
+
+// ReturnGraphRow appends the `RETURN` of a `GraphRow` for the preceding
+// `Traverse`, `ShortestPath` or `KShortestPaths`, ie. its `vertex`, `edge`
+// and `path` attributes, which with `QueryFlattened` become row-cells.
+// Without such a preceding statement, it appends nothing and `AQL.Build` fails.
+func (me *AQL) ReturnGraphRow() *AQL {
+	if me.graphVars == "" {
+		if me.err == nil {
+			me.err = errors.New("AQL.ReturnGraphRow: no preceding AQL.Traverse, AQL.ShortestPath or AQL.KShortestPaths")
+		}
+		return me
+	}
+	return me.Return(me.graphVars)
+}
+
+// GraphRow is a result document of an `AQL.ReturnGraphRow` query: for
+// `AQL.Traverse` all fields are set (except `Edge` for the start vertex),
+// for `AQL.ShortestPath` only `Vertex` and `Edge`, for `AQL.KShortestPaths` only `Path`.
+type GraphRow struct {
+	Vertex interface{}
+	Edge   interface{}
+	Path   *GraphPath
+
+	newVertex func() interface{}
+	newEdge   func() interface{}
+}
+
+// GraphPath is the `path` of a `GraphRow`.
+type GraphPath struct {
+	Vertices []interface{}
+	Edges    []interface{}
+	// only for `AQL.KShortestPaths`
+	Weight float64
+}
+
+// GraphRowsInto returns an `onReadDocDecodeIntoNewPtr` for `Query` that
+// decodes `AQL.ReturnGraphRow` results into `*GraphRow`s whose vertices and
+// edges (incl. those of `GraphRow.Path`) are decoded into new pointers from
+// `newVertex` and `newEdge`, or if `nil` into `map[string]interface{}`s.
+// Scan such row-cells via `Doc`, eg. `rows.Scan(&Doc{Into: &graphRow}, &meta)`.
+func GraphRowsInto(newVertex func() interface{}, newEdge func() interface{}) func(RowsCursor) interface{} {
+	return func(RowsCursor) interface{} { return &GraphRow{newVertex: newVertex, newEdge: newEdge} }
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`.
+func (me *GraphRow) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Vertex json.RawMessage `json:"vertex"`
+		Edge   json.RawMessage `json:"edge"`
+		Path   *struct {
+			Vertices []json.RawMessage `json:"vertices"`
+			Edges    []json.RawMessage `json:"edges"`
+			Weight   float64           `json:"weight"`
+		} `json:"path"`
+	}
+	if err = json.Unmarshal(data, &raw); err == nil {
+		if me.Vertex, err = graphDecode(raw.Vertex, me.newVertex); err == nil {
+			if me.Edge, err = graphDecode(raw.Edge, me.newEdge); err == nil && raw.Path != nil {
+				me.Path = &GraphPath{Weight: raw.Path.Weight,
+					Vertices: make([]interface{}, len(raw.Path.Vertices)), Edges: make([]interface{}, len(raw.Path.Edges))}
+				for i := 0; err == nil && i < len(raw.Path.Vertices); i++ {
+					me.Path.Vertices[i], err = graphDecode(raw.Path.Vertices[i], me.newVertex)
+				}
+				for i := 0; err == nil && i < len(raw.Path.Edges); i++ {
+					me.Path.Edges[i], err = graphDecode(raw.Path.Edges[i], me.newEdge)
+				}
+			}
+		}
+	}
+	return
+}
+
+func graphDecode(raw json.RawMessage, newPtr func() interface{}) (ptr interface{}, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if newPtr != nil {
+		ptr = newPtr()
+	} else {
+		ptr = &map[string]interface{}{}
+	}
+	err = json.Unmarshal(raw, ptr)
+	return
+}
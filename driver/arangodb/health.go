@@ -0,0 +1,75 @@
+package usqldrv_arango
+
+import (
+	"context"
+	sqldrv "database/sql/driver"
+	"errors"
+	"net"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// ServerInfo is returned by `Conn.ServerInfo`.
+type ServerInfo struct {
+	arango.VersionInfo
+	Engine arango.EngineType
+	Role   arango.ServerRole
+}
+
+// ServerInfo reports the version, storage engine and role of the server.
+func (me *arangoConn) ServerInfo(ctx context.Context) (info ServerInfo, err error) {
+	if info.VersionInfo, err = me.Client().Version(ctx); err == nil {
+		var engine arango.EngineInfo
+		if engine, err = me.EngineInfo(ctx); err == nil {
+			info.Engine = engine.Type
+			info.Role, err = me.Client().ServerRole(ctx)
+		}
+	}
+	err = me.errFrom(err)
+	return
+}
+
+// Ping implements `sqldrv.Pinger` by querying the server's version and (as of
+// ArangoDB 3.7) availability. On failure to reach a healthy server, it returns
+// `sqldrv.ErrBadConn` and this `Conn` is no longer valid for reuse. Other
+// failures (eg. authentication) are returned as per `Error`.
+func (me *arangoConn) Ping(ctx context.Context) (err error) {
+	if _, err = me.Client().Version(ctx); err == nil {
+		if _, err = me.do(ctx, "GET", "/_admin/server/availability", nil, 200, "", nil); arango.IsNotFound(err) {
+			err = nil // older servers lack that API
+		}
+	}
+	if isEndpointFailure(err) || arango.IsArangoErrorWithCode(err, 503) {
+		me.bad, err = true, sqldrv.ErrBadConn
+	}
+	return mapErr(err)
+}
+
+// ResetSession implements `sqldrv.SessionResetter`.
+func (me *arangoConn) ResetSession(context.Context) error {
+	if me.bad {
+		return sqldrv.ErrBadConn
+	}
+	return nil
+}
+
+// IsValid implements `sqldrv.Validator`.
+func (me *arangoConn) IsValid() bool { return !me.bad }
+
+// markBadOn marks this `Conn` as no longer valid for reuse if `err` is an endpoint failure.
+func (me *arangoConn) markBadOn(err error) {
+	if isEndpointFailure(err) {
+		me.bad = true
+	}
+}
+
+// isEndpointFailure tells whether `err` stems from failing to reach or hear back
+// from the server, rather than from the server refusing the request or from
+// cancellation or expiry of the `context.Context` it was sent with.
+func isEndpointFailure(err error) bool {
+	if err == nil || arango.IsArangoError(err) || arango.IsCanceled(err) || arango.IsTimeout(err) {
+		return false
+	}
+	var neterr net.Error
+	return arango.IsResponse(err) || errors.As(arango.Cause(err), &neterr)
+}
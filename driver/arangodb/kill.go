@@ -0,0 +1,77 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// KillQueryTimeout limits the duration of the requests that, upon cancellation
+// of the `context.Context` passed to `Conn.QueryContext` or `Conn.ExecContext`
+// (if from `QueryWith` with `QueryOptions.KillOnCancel`), find and kill the
+// still-running query on the server.
+var KillQueryTimeout = 4 * time.Second
+
+// newQueryMarker returns a unique AQL comment to append to a query's text,
+// by which it can be found among the server's currently-running queries.
+func newQueryMarker() string {
+	var id [12]byte
+	_, _ = rand.Read(id[:])
+	return "\n/* usqldrv_arango:" + hex.EncodeToString(id[:]) + " */"
+}
+
+// killQueries kills all currently running queries (in this `Conn`'s database) whose text contains `marker`.
+func (me *arangoConn) killQueries(marker string) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), KillQueryTimeout)
+	defer cancel()
+	var resp arango.Response
+	if resp, err = me.do(ctx, "GET", "_api/query/current", nil, 200, "", nil); err == nil {
+		var queries []arango.Response
+		if queries, err = resp.ParseArrayBody(); err == nil {
+			for _, query := range queries {
+				var running struct {
+					ID    string `json:"id"`
+					Query string `json:"query"`
+				}
+				if err = query.ParseBody("", &running); err == nil && strings.Contains(running.Query, marker) {
+					_, err = me.do(ctx, "DELETE", "_api/query/"+url.PathEscape(running.ID), nil, 200, "", nil)
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+func (me *arangoConn) killQueriesOnCancel(done <-chan struct{}, unwatch <-chan struct{}, marker string) {
+	select {
+	case <-unwatch:
+	case <-done:
+		_ = me.killQueries(marker)
+	}
+}
+
+func (me *arangoRowsCursor) stopWatchingCancel() {
+	if me.unwatch != nil {
+		close(me.unwatch)
+		me.unwatch = nil
+	}
+}
+
+// Close implements `sqldrv.Rows` (and `arango.Cursor`): it deletes the
+// server-side cursor (if any), which for a not-yet-fully-iterated streaming
+// query (see `QueryOptions.Stream`) also kills it.
+func (me *arangoRowsCursor) Close() (err error) {
+	me.stopWatchingCancel()
+	if err = me.Cursor.Close(); err != nil && arango.IsNotFound(err) {
+		err = nil // already gone, eg. due to expiry or being killed
+	}
+	return me.conn.errFrom(err)
+}
@@ -0,0 +1,82 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"database/sql"
+	sqldrv "database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestCancelKillsRunningQuery(t *testing.T) {
+	srv := newStandIn(t, true)
+	conn := srv.conn(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := conn.QueryContext(QueryWith(ctx, &QueryOptions{KillOnCancel: true}), "FOR d IN coll RETURN d", nil)
+		errs <- err
+	}()
+	if query := <-srv.started; !strings.Contains(query, "usqldrv_arango:") {
+		t.Fatalf("query lacks cancellation marker: %q", query)
+	}
+	cancel()
+	if err := <-errs; err == nil {
+		t.Fatal("expected an error from the cancelled query")
+	}
+	srv.await(t, "query q1 killed", func() bool { return len(srv.killed) == 1 && srv.killed[0] == "q1" })
+}
+
+func TestCloseBeforeEOFDeletesCursor(t *testing.T) {
+	srv := newStandIn(t, false)
+	rows, err := srv.conn(t).QueryContext(context.Background(), "FOR d IN coll RETURN d", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := make([]sqldrv.Value, len(rows.Columns()))
+	if err = rows.Next(cells); err != nil {
+		t.Fatal(err)
+	}
+	if err = rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv.await(t, "cursor 123 deleted", func() bool { return len(srv.deletedCursors) == 1 })
+	if len(srv.killed) != 0 {
+		t.Fatalf("expected no query kills, got: %v", srv.killed)
+	}
+}
+
+func TestCancelDuringIterationDeletesCursor(t *testing.T) {
+	srv := newStandIn(t, false)
+	db := sql.OpenDB(srv.connector(t))
+	defer db.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, err := db.QueryContext(ctx, "FOR d IN coll RETURN d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	cancel()
+	if rows.Next() {
+		t.Fatal("expected no more rows after cancellation")
+	}
+	srv.await(t, "cursor 123 deleted", func() bool { return len(srv.deletedCursors) == 1 })
+}
+
+func TestNoQueryMarkerUnlessKillOnCancel(t *testing.T) {
+	srv := newStandIn(t, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows, err := srv.conn(t).QueryContext(ctx, "FOR d IN coll RETURN d", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.queries) != 1 || srv.queries[0] != "FOR d IN coll RETURN d" {
+		t.Fatalf("expected the unmodified query text, got: %q", srv.queries)
+	}
+}
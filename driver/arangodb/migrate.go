@@ -0,0 +1,262 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// Migration is one versioned schema change to be applied (or reverted) by a
+// `Migrator`: declaratively via its `Steps`, and/or via its Go funcs.
+type Migration struct {
+	// must be unique and greater than 0
+	Version int64
+	Name    string
+	// `Up` ensures them in order, `Down` drops them in reverse order
+	Steps []SchemaStep
+	// if non-`nil`, called after ensuring the `Steps`
+	Up func(ctx context.Context, conn Conn) error
+	// if non-`nil`, called before dropping the `Steps`
+	Down func(ctx context.Context, conn Conn) error
+}
+
+// Migrator applies and reverts `Migrations`, recording the versions applied
+// so far in (and guarding concurrent runs by a lock document in) its `Collection`.
+type Migrator struct {
+	Migrations []Migration
+	// defaults to "_migrations", created as a system collection if missing
+	Collection string
+	// after which a lock left behind (eg. by a crashed runner) can be taken over, defaults to 10 minutes
+	// (while `Migrate` runs, its lock is renewed every third of this, so no need to exceed the longest `Migration`)
+	LockTTL time.Duration
+	// if so, `Migrate` changes nothing but still reports the planned changes
+	DryRun bool
+}
+
+// ErrMigrationLocked is returned by `Migrator.Migrate` if another runner holds the lock.
+var ErrMigrationLocked = errors.New("Migrate: another migration runner holds the lock")
+
+var errMigrationLockLost = fmt.Errorf("Migrate: lost the lock to another migration runner: %w", ErrMigrationLocked)
+
+const migrationLockKey = "lock"
+
+type migrationDoc struct {
+	Key       string    `json:"_key"`
+	Version   int64     `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+type migrationLockDoc struct {
+	Key       string    `json:"_key"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Migrate runs (in ascending order) the `Up` of all `Migrations` up to and
+// incl. `toVersion` not yet applied, after first running (in descending
+// order) the `Down` of all applied ones above it. So pass `math.MaxInt64`
+// to migrate all the way up, or 0 to revert all.
+//
+// Whether `DryRun` or not, `plan` describes the changes, one per `Steps` entry and Go func.
+// Should the lock be taken over meanwhile (see `LockTTL`), the `ctx` passed to
+// the `Steps` and Go funcs is cancelled, no further ones are run, and `err` is
+// then an `ErrMigrationLocked`.
+func (me *Migrator) Migrate(ctx context.Context, conn Conn, toVersion int64) (plan []string, err error) {
+	migrations, known := append(make([]Migration, 0, len(me.Migrations)), me.Migrations...), map[int64]bool{}
+	sort.Slice(migrations, func(i int, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := range migrations {
+		if version := migrations[i].Version; version <= 0 || known[version] {
+			return nil, errors.New("Migrate: migration versions must be unique and greater than 0, got: " + strconv.FormatInt(version, 10))
+		}
+		known[migrations[i].Version] = true
+	}
+
+	var coll arango.Collection
+	var applied map[int64]bool
+	if coll, err = me.collection(ctx, conn); err == nil && coll != nil && !me.DryRun {
+		var unlock func()
+		if ctx, unlock, err = me.lock(ctx, coll); err == nil {
+			defer unlock()
+		}
+	}
+	if err == nil {
+		applied, err = me.applied(ctx, conn, coll)
+	}
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	for version := range applied {
+		if version > toVersion && !known[version] {
+			return nil, errors.New("Migrate: cannot revert unknown applied migration version " + strconv.FormatInt(version, 10))
+		}
+	}
+
+	for i := len(migrations) - 1; err == nil && i >= 0; i-- {
+		if m := &migrations[i]; m.Version > toVersion && applied[m.Version] {
+			if plan, err = me.run(ctx, conn, m, false, plan); err == nil && !me.DryRun {
+				err = mapErr(removeDocument(ctx, coll, strconv.FormatInt(m.Version, 10)))
+			}
+		}
+	}
+	for i := 0; err == nil && i < len(migrations); i++ {
+		if m := &migrations[i]; m.Version <= toVersion && !applied[m.Version] {
+			if plan, err = me.run(ctx, conn, m, true, plan); err == nil && !me.DryRun {
+				_, err = coll.CreateDocument(ctx, &migrationDoc{Key: strconv.FormatInt(m.Version, 10), Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()})
+				err = mapErr(err)
+			}
+		}
+	}
+	if cause := context.Cause(ctx); err != nil && errors.Is(cause, ErrMigrationLocked) {
+		err = cause
+	}
+	return
+}
+
+func (me *Migrator) run(ctx context.Context, conn Conn, m *Migration, up bool, plan []string) ([]string, error) {
+	err := ctx.Err() // eg. the lock was lost meanwhile
+	prefix := strconv.FormatInt(m.Version, 10) + " " + m.Name + ": "
+	if up {
+		prefix = "up " + prefix
+		for i := 0; err == nil && i < len(m.Steps); i++ {
+			if plan = append(plan, prefix+"ensure "+m.Steps[i].String()); !me.DryRun {
+				err = m.Steps[i].Ensure(ctx, conn)
+			}
+		}
+		if err == nil && m.Up != nil {
+			if plan = append(plan, prefix+"call Up"); !me.DryRun {
+				err = m.Up(ctx, conn)
+			}
+		}
+	} else {
+		prefix = "down " + prefix
+		if err == nil && m.Down == nil && len(m.Steps) == 0 {
+			err = errors.New("neither Down nor Steps to revert")
+		} else if err == nil && m.Down != nil {
+			if plan = append(plan, prefix+"call Down"); !me.DryRun {
+				err = m.Down(ctx, conn)
+			}
+		}
+		for i := len(m.Steps) - 1; err == nil && i >= 0; i-- {
+			if plan = append(plan, prefix+"drop "+m.Steps[i].String()); !me.DryRun {
+				err = m.Steps[i].Drop(ctx, conn)
+			}
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("Migrate: %s%w", prefix, mapErr(err))
+	}
+	return plan, err
+}
+
+func (me *Migrator) collection(ctx context.Context, conn Conn) (coll arango.Collection, err error) {
+	name := me.Collection
+	if name == "" {
+		name = "_migrations"
+	}
+	var exists bool
+	if me.DryRun {
+		if exists, err = conn.CollectionExists(ctx, name); err == nil && exists {
+			coll, err = conn.Collection(ctx, name)
+		}
+	} else if err = (&CollectionSpec{Name: name}).Ensure(ctx, conn); err == nil {
+		coll, err = conn.Collection(ctx, name)
+	}
+	return
+}
+
+func (me *Migrator) applied(ctx context.Context, conn Conn, coll arango.Collection) (applied map[int64]bool, err error) {
+	applied = map[int64]bool{}
+	if coll == nil { // dry run with no migrations collection yet
+		return
+	}
+	var cursor arango.Cursor
+	if cursor, err = conn.Query(ctx, "FOR d IN @@coll FILTER d._key != @lock RETURN d", map[string]interface{}{"@coll": coll.Name(), "lock": migrationLockKey}); err == nil {
+		defer cursor.Close()
+		for err == nil && cursor.HasMore() {
+			var doc migrationDoc
+			if _, err = cursor.ReadDocument(ctx, &doc); err == nil {
+				applied[doc.Version] = true
+			}
+		}
+	}
+	return
+}
+
+// lock inserts the lock document, taking over an expired one if need be,
+// and keeps renewing its `ExpiresAt` in the background until `unlock`. The
+// returned `lockCtx` (from `ctx`) is cancelled should the lock be lost meanwhile.
+func (me *Migrator) lock(ctx context.Context, coll arango.Collection) (lockCtx context.Context, unlock func(), err error) {
+	lockCtx = ctx
+	ttl := me.LockTTL
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	var meta arango.DocumentMeta
+	for attempt := 0; attempt < 2; attempt++ {
+		if meta, err = coll.CreateDocument(ctx, &migrationLockDoc{Key: migrationLockKey, ExpiresAt: time.Now().UTC().Add(ttl)}); !arango.IsConflict(err) {
+			break
+		}
+		var held migrationLockDoc
+		var heldmeta arango.DocumentMeta
+		if heldmeta, err = coll.ReadDocument(ctx, migrationLockKey, &held); arango.IsNotFound(err) {
+			continue // released meanwhile
+		} else if err == nil {
+			if err = ErrMigrationLocked; time.Now().After(held.ExpiresAt) {
+				if _, err = coll.RemoveDocument(arango.WithRevision(ctx, heldmeta.Rev), migrationLockKey); err == nil || arango.IsNotFound(err) || arango.IsPreconditionFailed(err) {
+					err = ErrMigrationLocked // to be overwritten by the next attempt
+					continue
+				}
+			}
+		}
+		break
+	}
+	if err == nil {
+		var lost context.CancelCauseFunc
+		lockCtx, lost = context.WithCancelCause(ctx)
+		renewctx, stop := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go renewLock(renewctx, coll, ttl, &meta, lost, stopped)
+		unlock = func() {
+			stop()
+			<-stopped
+			lost(nil)
+			_, _ = coll.RemoveDocument(arango.WithRevision(context.Background(), meta.Rev), migrationLockKey)
+		}
+	}
+	return
+}
+
+// renewLock extends the lock's `ExpiresAt` every third of `ttl` (tracking its
+// new revision in `meta`) until `ctx` is done, or until the lock was taken over
+// (then reported via `lost`).
+func renewLock(ctx context.Context, coll arango.Collection, ttl time.Duration, meta *arango.DocumentMeta, lost context.CancelCauseFunc, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := coll.UpdateDocument(arango.WithRevision(ctx, meta.Rev), migrationLockKey, map[string]interface{}{"expiresAt": time.Now().UTC().Add(ttl)})
+			if err == nil {
+				*meta = renewed
+			} else if arango.IsPreconditionFailed(err) || arango.IsNotFound(err) {
+				lost(errMigrationLockLost) // so `unlock` won't remove it either
+				return
+			} // else (eg. connectivity), retry on the next tick
+		}
+	}
+}
+
+func removeDocument(ctx context.Context, coll arango.Collection, key string) (err error) {
+	if _, err = coll.RemoveDocument(ctx, key); arango.IsNotFound(err) {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,214 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMigrations(calls *[]string, versions ...int64) (migrations []Migration) {
+	for _, version := range versions {
+		name := "m" + strconv.FormatInt(version, 10)
+		migrations = append(migrations, Migration{Version: version, Name: name,
+			Up:   func(context.Context, Conn) error { *calls = append(*calls, "up "+name); return nil },
+			Down: func(context.Context, Conn) error { *calls = append(*calls, "down "+name); return nil },
+		})
+	}
+	return
+}
+
+func (me *standIn) docKeys(coll string) (keys []string) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	for key := range me.colls[coll] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func (me *standIn) seedLock(expiresAt time.Time) {
+	me.colls["_migrations"] = map[string]map[string]interface{}{
+		"lock": {"_id": "_migrations/lock", "_key": "lock", "_rev": "r0", "expiresAt": expiresAt.UTC().Format(time.RFC3339Nano)},
+	}
+}
+
+func TestMigrateUpAndDownInVersionOrder(t *testing.T) {
+	srv := newStandIn(t, false)
+	conn := srv.conn(t)
+	var calls []string
+	migrator := &Migrator{Migrations: testMigrations(&calls, 3, 1, 2)}
+
+	plan, err := migrator.Migrate(context.Background(), conn, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"up m1", "up m2", "up m3"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls:\n got: %q\nwant: %q", calls, want)
+	}
+	if want := []string{"up 1 m1: call Up", "up 2 m2: call Up", "up 3 m3: call Up"}; !reflect.DeepEqual(plan, want) {
+		t.Fatalf("plan:\n got: %q\nwant: %q", plan, want)
+	}
+	if keys := srv.docKeys("_migrations"); !reflect.DeepEqual(keys, []string{"1", "2", "3"}) {
+		t.Fatalf("expected applied versions 1, 2, 3 and no lock left behind, got: %q", keys)
+	}
+
+	calls = nil
+	if _, err = migrator.Migrate(context.Background(), conn, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"down m3", "down m2"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls:\n got: %q\nwant: %q", calls, want)
+	}
+	if keys := srv.docKeys("_migrations"); !reflect.DeepEqual(keys, []string{"1"}) {
+		t.Fatalf("expected applied version 1 only, got: %q", keys)
+	}
+}
+
+func TestMigrateRejectsInvalidVersions(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		versions []int64
+	}{
+		{name: "zero", versions: []int64{1, 0}},
+		{name: "negative", versions: []int64{-1}},
+		{name: "duplicate", versions: []int64{2, 1, 2}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var calls []string
+			migrator := &Migrator{Migrations: testMigrations(&calls, test.versions...)}
+			if _, err := migrator.Migrate(context.Background(), nil, math.MaxInt64); err == nil || !strings.Contains(err.Error(), "must be unique and greater than 0") {
+				t.Fatalf("expected a version error, got: %v", err)
+			}
+			if len(calls) != 0 {
+				t.Fatalf("expected no calls, got: %q", calls)
+			}
+		})
+	}
+}
+
+func TestMigrateRejectsRevertOfUnknownVersion(t *testing.T) {
+	srv := newStandIn(t, false)
+	srv.colls["_migrations"] = map[string]map[string]interface{}{
+		"1": {"_id": "_migrations/1", "_key": "1", "_rev": "r0", "version": 1},
+		"7": {"_id": "_migrations/7", "_key": "7", "_rev": "r0", "version": 7},
+	}
+	var calls []string
+	_, err := (&Migrator{Migrations: testMigrations(&calls, 1)}).Migrate(context.Background(), srv.conn(t), 0)
+	if err == nil || !strings.Contains(err.Error(), "unknown applied migration version 7") {
+		t.Fatalf("expected an unknown-version error, got: %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no calls, got: %q", calls)
+	}
+	if keys := srv.docKeys("_migrations"); !reflect.DeepEqual(keys, []string{"1", "7"}) {
+		t.Fatalf("expected no changes and no lock left behind, got: %q", keys)
+	}
+}
+
+func TestMigrateDryRunPlansOnly(t *testing.T) {
+	srv := newStandIn(t, false)
+	var calls []string
+	migrations := testMigrations(&calls, 2, 1)
+	migrations[0].Steps = []SchemaStep{&CollectionSpec{Name: "users"}, &IndexSpec{Collection: "users", Kind: IndexPersistent, Name: "byName", Fields: []string{"name"}}}
+	plan, err := (&Migrator{Migrations: migrations, DryRun: true}).Migrate(context.Background(), srv.conn(t), math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{
+		"up 1 m1: call Up",
+		"up 2 m2: ensure collection users",
+		"up 2 m2: ensure persistent index byName(name) on users",
+		"up 2 m2: call Up",
+	}; !reflect.DeepEqual(plan, want) {
+		t.Fatalf("plan:\n got: %q\nwant: %q", plan, want)
+	}
+	if len(calls) != 0 || len(srv.colls) != 0 {
+		t.Fatalf("expected no changes, got calls %q and collections %v", calls, srv.colls)
+	}
+}
+
+func TestMigrateLockHeld(t *testing.T) {
+	srv := newStandIn(t, false)
+	srv.seedLock(time.Now().Add(time.Hour))
+	var calls []string
+	if _, err := (&Migrator{Migrations: testMigrations(&calls, 1)}).Migrate(context.Background(), srv.conn(t), math.MaxInt64); err != ErrMigrationLocked {
+		t.Fatalf("expected ErrMigrationLocked, got: %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no calls, got: %q", calls)
+	}
+	if srv.colls["_migrations"]["lock"]["_rev"] != "r0" {
+		t.Fatalf("expected the held lock untouched, got: %v", srv.colls["_migrations"]["lock"])
+	}
+}
+
+func TestMigrateTakesOverExpiredLock(t *testing.T) {
+	srv := newStandIn(t, false)
+	srv.seedLock(time.Now().Add(-time.Second))
+	var calls []string
+	if _, err := (&Migrator{Migrations: testMigrations(&calls, 1)}).Migrate(context.Background(), srv.conn(t), math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"up m1"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls:\n got: %q\nwant: %q", calls, want)
+	}
+	if keys := srv.docKeys("_migrations"); !reflect.DeepEqual(keys, []string{"1"}) {
+		t.Fatalf("expected applied version 1 and no lock left behind, got: %q", keys)
+	}
+}
+
+func TestMigrateRenewsLock(t *testing.T) {
+	srv := newStandIn(t, false)
+	migrator := &Migrator{LockTTL: 150 * time.Millisecond, Migrations: []Migration{{Version: 1, Name: "slow",
+		Up: func(context.Context, Conn) error {
+			srv.mu.Lock()
+			rev, expiresAt := srv.colls["_migrations"]["lock"]["_rev"], srv.colls["_migrations"]["lock"]["expiresAt"]
+			srv.mu.Unlock()
+			srv.await(t, "lock renewed", func() bool {
+				lock := srv.colls["_migrations"]["lock"]
+				return lock["_rev"] != rev && lock["expiresAt"] != expiresAt
+			})
+			return nil
+		},
+	}}}
+	if _, err := migrator.Migrate(context.Background(), srv.conn(t), math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if keys := srv.docKeys("_migrations"); !reflect.DeepEqual(keys, []string{"1"}) {
+		t.Fatalf("expected the renewed lock removed, got: %q", keys)
+	}
+}
+
+func TestMigrateStopsOnLostLock(t *testing.T) {
+	srv := newStandIn(t, false)
+	var calls []string
+	migrations := testMigrations(&calls, 1, 2)
+	migrations[0].Up = func(ctx context.Context, _ Conn) error {
+		srv.mu.Lock()
+		srv.colls["_migrations"]["lock"]["_rev"] = "taken-over" // by another runner
+		srv.mu.Unlock()
+		select {
+		case <-ctx.Done():
+		case <-time.After(4 * time.Second):
+			t.Error("timed out awaiting the lost lock's ctx cancellation")
+		}
+		return nil
+	}
+	_, err := (&Migrator{LockTTL: 150 * time.Millisecond, Migrations: migrations}).Migrate(context.Background(), srv.conn(t), math.MaxInt64)
+	if !errors.Is(err, ErrMigrationLocked) {
+		t.Fatalf("expected ErrMigrationLocked, got: %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no further calls, got: %q", calls)
+	}
+	if keys := srv.docKeys("_migrations"); !reflect.DeepEqual(keys, []string{"lock"}) || srv.colls["_migrations"]["lock"]["_rev"] != "taken-over" {
+		t.Fatalf("expected no version recorded and the other runner's lock untouched, got: %q", keys)
+	}
+}
@@ -2,8 +2,12 @@ package usqldrv_arango
 
 import (
 	"context"
+	"database/sql"
 	sqldrv "database/sql/driver"
+	"encoding/json"
 	"io"
+	"reflect"
+	"time"
 
 	arango "github.com/arangodb/go-driver"
 )
@@ -12,30 +16,196 @@ const (
 	// both returned by our `sqldrv.Rows.Columns()` implementation:
 	ColNameDoc  = "_doc"  // map[string]interface{} or other (see `RowsCursor`)
 	ColNameMeta = "_meta" // always an arango.DocumentMeta
+
+	// both returned by our `sqldrv.RowsColumnTypeDatabaseTypeName` implementation:
+	ColTypeNameDoc  = "JSON"
+	ColTypeNameMeta = "DOCUMENT_META"
+)
+
+var (
+	colTypeDocDefault = reflect.TypeOf(&map[string]interface{}{})
+	colTypeMeta       = reflect.TypeOf(arango.DocumentMeta{})
+	colTypeFlat       = reflect.TypeOf((*interface{})(nil)).Elem()
 )
 
 type queryCtx struct {
 	context.Context
 	OnReadDocDecodeIntoNewPtr func(RowsCursor) interface{}
+	Flattened                 bool
+	FlattenedColumns          []string
+	KillOnCancel              bool
+	Batch                     []BatchQuery
+}
+
+// Value implements `context.Context`.
+func (me *queryCtx) Value(key interface{}) interface{} {
+	if key == ctxKeyQuery {
+		return me
+	}
+	return me.Context.Value(key)
 }
 
 // Query returns a `context.Context` that can be passed to `Conn.QueryContext`.
 func Query(ctx context.Context, wantCountInRowsCursor bool, onReadDocDecodeIntoNewPtr func(RowsCursor) interface{}) context.Context {
-	if wantCountInRowsCursor {
-		ctx = arango.WithQueryCount(ctx)
+	return QueryWith(ctx, &QueryOptions{Count: wantCountInRowsCursor, OnReadDocDecodeIntoNewPtr: onReadDocDecodeIntoNewPtr})
+}
+
+// QueryOptions configures `QueryWith`. Zero values imply ArangoDB's defaults.
+type QueryOptions struct {
+	// as per `Query`
+	Count bool
+	// as per `Query`
+	OnReadDocDecodeIntoNewPtr func(RowsCursor) interface{}
+	// as per `QueryFlattened`
+	Flattened bool
+	// as per `QueryFlattened`
+	FlattenedColumns []string
+	// if the `context.Context` can be cancelled, appends a unique comment to
+	// the query text by which, upon cancellation, the query is then found and
+	// killed on the server if still running. As ArangoDB keys its query results
+	// cache and plan cache on the exact query text, such queries never hit them.
+	KillOnCancel bool
+
+	// max. number of result documents transferred per server round-trip
+	BatchSize int
+	// how long the server keeps the cursor alive between round-trips
+	TTL time.Duration
+	// max. memory in bytes the query may use
+	MemoryLimit int64
+	// max. duration the query may run before being killed by the server
+	MaxRuntime time.Duration
+	// makes `RowsCursor.Statistics().FullCount()` available
+	FullCount bool
+	// evaluate the query lazily while iterating, rather than all up-front
+	Stream bool
+	// if non-`nil`, turns the query results cache on or off for the query
+	Cache *bool
+	// 1 to profile the query, 2 to also include the profiled plan
+	Profile int
+	// eg. "-all" or "+use-indexes"
+	OptimizerRules []string
+}
+
+// QueryWith returns a `context.Context` from `ctx` that can be passed to
+// `Conn.QueryContext` to run the query as configured by `options`.
+func QueryWith(ctx context.Context, options *QueryOptions) context.Context {
+	qctx := queryCtx{}
+	if batch, _ := ctx.Value(ctxKeyQuery).(*queryCtx); batch != nil {
+		qctx.Batch = batch.Batch
 	}
-	return &queryCtx{Context: ctx, OnReadDocDecodeIntoNewPtr: onReadDocDecodeIntoNewPtr}
+	if options != nil {
+		qctx.OnReadDocDecodeIntoNewPtr, qctx.Flattened, qctx.FlattenedColumns = options.OnReadDocDecodeIntoNewPtr, options.Flattened, options.FlattenedColumns
+		qctx.KillOnCancel = options.KillOnCancel
+		if options.Count {
+			ctx = arango.WithQueryCount(ctx)
+		}
+		if options.BatchSize > 0 {
+			ctx = arango.WithQueryBatchSize(ctx, options.BatchSize)
+		}
+		if options.TTL > 0 {
+			ctx = arango.WithQueryTTL(ctx, options.TTL)
+		}
+		if options.MemoryLimit > 0 {
+			ctx = arango.WithQueryMemoryLimit(ctx, options.MemoryLimit)
+		}
+		if options.MaxRuntime > 0 {
+			ctx = arango.WithQueryMaxRuntime(ctx, options.MaxRuntime.Seconds())
+		}
+		if options.FullCount {
+			ctx = arango.WithQueryFullCount(ctx)
+		}
+		if options.Stream {
+			ctx = arango.WithQueryStream(ctx)
+		}
+		if options.Cache != nil {
+			ctx = arango.WithQueryCache(ctx, *options.Cache)
+		}
+		if options.Profile > 0 {
+			ctx = arango.WithQueryProfile(ctx, options.Profile)
+		}
+		if len(options.OptimizerRules) > 0 {
+			ctx = arango.WithQueryOptimizerRules(ctx, options.OptimizerRules)
+		}
+	}
+	qctx.Context = ctx
+	return &qctx
+}
+
+// BatchQuery is one of the further queries of a `QueryBatch`. Its `Args` are
+// positional, or named if `sql.NamedArg`s, as for `sql.DB.QueryContext`.
+type BatchQuery struct {
+	Query string
+	Args  []interface{}
+}
+
+// QueryBatch returns a `context.Context` from `ctx` (which may be from `Query`,
+// `QueryWith` or `QueryFlattened`) that can be passed to `Conn.QueryContext` to
+// have its `query` followed by all the `queries`, each run once `sql.Rows.NextResultSet`
+// advances to its result set (with the same `QueryOptions` as the first one).
+func QueryBatch(ctx context.Context, queries ...BatchQuery) context.Context {
+	qctx, _ := ctx.Value(ctxKeyQuery).(*queryCtx)
+	if qctx == nil {
+		qctx = &queryCtx{}
+	}
+	batch := *qctx
+	batch.Context, batch.Batch = ctx, queries
+	return &batch
 }
 
 // QueryContext implements `sqldrv.QueryerContext` and if no `err`, `rows` will always implement this package's `RowsCursor` interface.
+//
+// If `ctx` can be cancelled and is from `QueryWith` with `QueryOptions.KillOnCancel`,
+// the `query` is killed on the server upon cancellation if still running.
+//
+// If `ctx` is from `Transact`, `query` is run as a JavaScript transaction and
+// the `rows` are its result: one per element if an array, else just the one
+// (or none if `null`). Their `ColNameDoc` cells are then `driver.Value`s (as
+// per `QueryFlattened`) rather than pointers, for use with `sql.Row.Scan`.
 func (me *arangoConn) QueryContext(ctx context.Context, query string, args []sqldrv.NamedValue) (rows sqldrv.Rows, err error) {
 	var rowcur arangoRowsCursor
-	if qctx, _ := ctx.(*queryCtx); qctx != nil {
-		rowcur.onReadDocIntoNewPtr = qctx.OnReadDocDecodeIntoNewPtr
+	var wastransact bool
+	var result interface{}
+	if wastransact, result, err = me.transactMaybe(ctx, query, args); wastransact {
+		if err == nil {
+			if rowcur.Cursor, err = newTransactCursor(result); err == nil {
+				rows, rowcur.conn, rowcur.ctx, rowcur.valueCells = &rowcur, me, ctx, true
+			}
+		}
+		return rows, me.errFrom(err)
 	}
-	if rowcur.Cursor, err = me.Database.Query(ctx, query, me.bindVarsFrom(args)); err == nil {
-		rows, rowcur.conn, rowcur.ctx = &rowcur, me, ctx
+	var killoncancel bool
+	var batchcur *batchRowsCursor
+	if qctx, _ := ctx.Value(ctxKeyQuery).(*queryCtx); qctx != nil {
+		killoncancel = qctx.KillOnCancel
+		if rowcur.onReadDocIntoNewPtr = qctx.OnReadDocDecodeIntoNewPtr; qctx.Flattened {
+			rowcur.flat = &flatRows{cols: qctx.FlattenedColumns}
+		}
+		if len(qctx.Batch) > 0 {
+			nobatch := *qctx
+			nobatch.Batch = nil
+			batchcur = &batchRowsCursor{arangoRowsCursor: &rowcur, batch: qctx.Batch, ctx: &nobatch}
+		}
+	}
+	var bindvars map[string]interface{}
+	if bindvars, err = me.bindVarsFrom(args); err == nil {
+		if rowcur.conn = me; killoncancel && ctx.Done() != nil {
+			marker := newQueryMarker()
+			query, rowcur.unwatch = query+marker, make(chan struct{})
+			go me.killQueriesOnCancel(ctx.Done(), rowcur.unwatch, marker)
+		}
+		ctx = arango.WithRawResponse(me.txCtx(ctx), &rowcur.rawResp)
+		if rowcur.Cursor, err = me.Database.Query(ctx, query, bindvars); err == nil {
+			if rows, rowcur.ctx = &rowcur, ctx; batchcur != nil {
+				rows = batchcur
+			}
+			if !rowcur.HasMore() {
+				rowcur.stopWatchingCancel() // no server-side cursor (or still-running streaming query)
+			}
+		} else if ctx.Err() == nil {
+			rowcur.stopWatchingCancel()
+		}
 	}
+	err = me.errFrom(err)
 	return
 }
 
@@ -48,10 +218,16 @@ func (me *arangoConn) QueryContext(ctx context.Context, query string, args []sql
 // a well-typed (rather than generic `map[string]interface{}`) value.
 type RowsCursor interface {
 	sqldrv.Rows
+	sqldrv.RowsNextResultSet
+	sqldrv.RowsColumnTypeScanType
+	sqldrv.RowsColumnTypeDatabaseTypeName
 	Conn() Conn
 	Context() context.Context
 	// only if `wantCountInRowsCursor` in your `Query`
 	Count() int64
+	QueryStats() QueryStats
+	QueryWarnings() []QueryWarning
+	QueryProfile() json.RawMessage
 }
 
 type arangoRowsCursor struct {
@@ -59,38 +235,131 @@ type arangoRowsCursor struct {
 	ctx                 context.Context
 	conn                *arangoConn
 	onReadDocIntoNewPtr func(RowsCursor) interface{}
+	docType             reflect.Type
+	flat                *flatRows
+	valueCells          bool
+	rawResp             []byte
+	rawExtra            []byte
+	extra               *queryExtra
+	unwatch             chan struct{}
 	eof                 bool
 }
 
+// batchRowsCursor is the `RowsCursor` of a `QueryBatch`, forwarding to the
+// `arangoRowsCursor` of the current result set.
+type batchRowsCursor struct {
+	*arangoRowsCursor
+	batch []BatchQuery
+	ctx   context.Context
+}
+
 var rowsCursorColumns = []string{ColNameDoc, ColNameMeta}
 
 // Columns implements `sqldrv.Rows`
-func (me *arangoRowsCursor) Columns() (cols []string) { return rowsCursorColumns }
+func (me *arangoRowsCursor) Columns() (cols []string) {
+	if me.flat != nil {
+		return me.flatColumns()
+	}
+	return rowsCursorColumns
+}
+
 func (me *arangoRowsCursor) Conn() Conn               { return me.conn }
 func (me *arangoRowsCursor) Context() context.Context { return me.ctx }
 
+// ColumnTypeScanType implements `sqldrv.RowsColumnTypeScanType`. For the
+// `ColNameDoc` column, that's the type of the pointers produced by the
+// `onReadDocDecodeIntoNewPtr` given to `Query` (else `*map[string]interface{}`).
+func (me *arangoRowsCursor) ColumnTypeScanType(index int) reflect.Type {
+	if me.flat != nil || (me.valueCells && index == 0) {
+		return colTypeFlat
+	} else if index != 0 {
+		return colTypeMeta
+	} else if me.docType == nil {
+		if me.docType = colTypeDocDefault; me.onReadDocIntoNewPtr != nil {
+			me.docType = reflect.TypeOf(me.onReadDocIntoNewPtr(me))
+		}
+	}
+	return me.docType
+}
+
+// ColumnTypeDatabaseTypeName implements `sqldrv.RowsColumnTypeDatabaseTypeName`.
+func (me *arangoRowsCursor) ColumnTypeDatabaseTypeName(index int) string {
+	if index != 0 && me.flat == nil {
+		return ColTypeNameMeta
+	}
+	return ColTypeNameDoc
+}
+
 // Next implements `sqldrv.Rows`
 func (me *arangoRowsCursor) Next(cells []sqldrv.Value) (err error) {
+	if me.flat != nil {
+		return me.nextFlat(cells)
+	}
 	if !me.eof {
 		me.eof = !me.HasMore()
 	}
 	if !me.eof {
 		var obj interface{}
-		if me.onReadDocIntoNewPtr != nil {
+		if me.valueCells {
+			obj = new(json.RawMessage)
+		} else if me.onReadDocIntoNewPtr != nil {
 			obj = me.onReadDocIntoNewPtr(me)
 		} else {
 			foo := map[string]interface{}{}
 			obj = &foo
 		}
 		var meta arango.DocumentMeta
-		if meta, err = me.ReadDocument(me.ctx, obj); err == nil {
-			cells[0], cells[1] = obj, meta
+		if me.docType == nil {
+			me.docType = reflect.TypeOf(obj)
+		}
+		meta, err = me.ReadDocument(me.ctx, obj)
+		if me.onRawResponse(); err == nil {
+			if cells[0], cells[1] = obj, meta; me.valueCells {
+				cells[0], err = flatValue(*obj.(*json.RawMessage))
+			}
 		} else {
 			me.eof = arango.IsNoMoreDocuments(err)
 		}
 	}
 	if me.eof {
+		me.stopWatchingCancel()
 		err = io.EOF // to conform with sqldrv.Rows
+	} else if err != nil {
+		err = me.conn.errFrom(err)
+	}
+	return
+}
+
+// HasNextResultSet implements `sqldrv.RowsNextResultSet`, see `QueryBatch`.
+func (*arangoRowsCursor) HasNextResultSet() bool { return false }
+
+// NextResultSet implements `sqldrv.RowsNextResultSet`, see `QueryBatch`.
+func (*arangoRowsCursor) NextResultSet() error { return io.EOF }
+
+// HasNextResultSet implements `sqldrv.RowsNextResultSet`.
+func (me *batchRowsCursor) HasNextResultSet() bool { return len(me.batch) > 0 }
+
+// NextResultSet implements `sqldrv.RowsNextResultSet` by closing the current
+// cursor and running the next query of the `QueryBatch`.
+func (me *batchRowsCursor) NextResultSet() (err error) {
+	if len(me.batch) == 0 {
+		return io.EOF
+	}
+	next, args := me.batch[0], make([]sqldrv.NamedValue, len(me.batch[0].Args))
+	for i, arg := range next.Args {
+		args[i].Ordinal, args[i].Value = i+1, arg
+		if named, ok := arg.(sql.NamedArg); ok {
+			args[i].Name, args[i].Value = named.Name, named.Value
+		}
+		if err = me.conn.CheckNamedValue(&args[i]); err != nil {
+			return
+		}
+	}
+	if err = me.Close(); err == nil {
+		var rows sqldrv.Rows
+		if rows, err = me.conn.QueryContext(me.ctx, next.Query, args); err == nil {
+			me.arangoRowsCursor, me.batch = rows.(*arangoRowsCursor), me.batch[1:]
+		}
 	}
 	return
 }
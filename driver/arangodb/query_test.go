@@ -0,0 +1,57 @@
+package usqldrv_arango
+
+import (
+	"context"
+	sqldrv "database/sql/driver"
+	"io"
+	"testing"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+func TestQueryBatchStatsPerResultSet(t *testing.T) {
+	srv := newStandIn(t, false)
+	srv.batches = map[string][]map[string]interface{}{
+		"FOR d IN a RETURN d": {
+			{"result": []interface{}{map[string]interface{}{"_key": "a1"}}, "extra": map[string]interface{}{"stats": map[string]interface{}{"scannedFull": 1}}},
+		},
+		"FOR d IN b RETURN d": {
+			{"result": []interface{}{map[string]interface{}{"_key": "b1"}}},
+			{"result": []interface{}{map[string]interface{}{"_key": "b2"}}, "extra": map[string]interface{}{"stats": map[string]interface{}{"scannedFull": 42}}},
+		},
+	}
+	ctx := QueryBatch(QueryWith(context.Background(), &QueryOptions{Stream: true}), BatchQuery{Query: "FOR d IN b RETURN d"})
+	rows, err := srv.conn(t).QueryContext(ctx, "FOR d IN a RETURN d", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowcur := rows.(RowsCursor)
+	defer rowcur.Close()
+
+	var keys []string
+	var stats []int64
+	for {
+		cells := make([]sqldrv.Value, len(rowcur.Columns()))
+		for err = rowcur.Next(cells); err == nil; err = rowcur.Next(cells) {
+			keys = append(keys, cells[1].(arango.DocumentMeta).Key)
+		}
+		if err != io.EOF {
+			t.Fatal(err)
+		}
+		stats = append(stats, rowcur.QueryStats().ScannedFull)
+		if !rowcur.HasNextResultSet() {
+			break
+		} else if err = rowcur.NextResultSet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(keys) != 3 || keys[0] != "a1" || keys[1] != "b1" || keys[2] != "b2" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	if len(stats) != 2 || stats[0] != 1 || stats[1] != 42 {
+		t.Fatalf("unexpected ScannedFull per result set: %v", stats)
+	}
+	if err = rowcur.NextResultSet(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the last result set, got: %v", err)
+	}
+}
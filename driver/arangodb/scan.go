@@ -0,0 +1,85 @@
+package usqldrv_arango
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// Doc implements `sql.Scanner` to decode a `ColNameDoc` row-cell (or, with
+// `QueryFlattened`, any object or array cell) into `Into`, which must be a
+// non-`nil` pointer, eg. `rows.Scan(&Doc{Into: &myStruct}, &meta)`.
+//
+// If the row-cell already is of the same pointer type as `Into` (as produced
+// by a `Query`'s `onReadDocDecodeIntoNewPtr`), it is merely dereferenced into
+// `Into`, otherwise it is JSON-marshaled (unless already JSON, ie. `[]byte` or
+// `json.RawMessage`, but not `string`s which are decoded values) and -unmarshaled.
+// For cheapest scanning, pass `DocRaw` as `Query`'s `onReadDocDecodeIntoNewPtr`.
+type Doc struct {
+	Into interface{}
+}
+
+// DocRaw can be passed as `onReadDocDecodeIntoNewPtr` to `Query` so that the
+// `ColNameDoc` row-cells are `*json.RawMessage`s, to be decoded later via `Doc`.
+func DocRaw(RowsCursor) interface{} { return new(json.RawMessage) }
+
+// Scan implements `sql.Scanner`
+func (me *Doc) Scan(src interface{}) (err error) {
+	dst := reflect.ValueOf(me.Into)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return errors.New("Doc.Into must be a non-nil pointer")
+	}
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		dst.Elem().Set(reflect.Zero(dst.Elem().Type()))
+		return
+	case []byte:
+		data = src
+	case json.RawMessage:
+		data = src
+	case *json.RawMessage:
+		if src != nil {
+			data = *src
+		}
+	default:
+		if srcval := reflect.ValueOf(src); srcval.Type() == dst.Type() {
+			if !srcval.IsNil() {
+				dst.Elem().Set(srcval.Elem())
+			}
+			return
+		}
+		data, err = json.Marshal(src)
+	}
+	if err == nil {
+		err = json.Unmarshal(data, me.Into)
+	}
+	return
+}
+
+// Meta implements `sql.Scanner` to read a `ColNameMeta` row-cell (or, with
+// `QueryFlattened`, any cell holding an object with `_key`, `_id`, `_rev`).
+type Meta struct {
+	arango.DocumentMeta
+}
+
+// Scan implements `sql.Scanner`
+func (me *Meta) Scan(src interface{}) (err error) {
+	switch src := src.(type) {
+	case nil:
+		me.DocumentMeta = arango.DocumentMeta{}
+	case arango.DocumentMeta:
+		me.DocumentMeta = src
+	case *arango.DocumentMeta:
+		me.DocumentMeta = *src
+	case []byte:
+		err = json.Unmarshal(src, &me.DocumentMeta)
+	case string:
+		err = json.Unmarshal([]byte(src), &me.DocumentMeta)
+	default:
+		err = errors.New("cannot scan a " + reflect.TypeOf(src).String() + " into a Meta")
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package usqldrv_arango
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDocScan(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	raw := json.RawMessage(`{"name":"raw"}`)
+	for _, test := range []struct {
+		name string
+		src  interface{}
+		into func() interface{}
+		want interface{}
+	}{
+		{name: "nil", src: nil, into: func() interface{} { return &item{Name: "old"} }, want: &item{}},
+		{name: "string", src: "hello", into: func() interface{} { return new(string) }, want: ptrTo("hello")},
+		{name: "int64", src: int64(42), into: func() interface{} { return new(int) }, want: ptrTo(42)},
+		{name: "float64", src: 4.2, into: func() interface{} { return new(float64) }, want: ptrTo(4.2)},
+		{name: "same-pointer", src: &item{Name: "same"}, into: func() interface{} { return new(item) }, want: &item{Name: "same"}},
+		{name: "map-pointer", src: &map[string]interface{}{"name": "map"}, into: func() interface{} { return new(item) }, want: &item{Name: "map"}},
+		{name: "bytes", src: []byte(`{"name":"bytes"}`), into: func() interface{} { return new(item) }, want: &item{Name: "bytes"}},
+		{name: "raw-message", src: raw, into: func() interface{} { return new(item) }, want: &item{Name: "raw"}},
+		{name: "raw-message-pointer", src: &raw, into: func() interface{} { return new(item) }, want: &item{Name: "raw"}},
+	} {
+		into := test.into()
+		if err := (&Doc{Into: into}).Scan(test.src); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !reflect.DeepEqual(into, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.name, into, test.want)
+		}
+	}
+	if err := (&Doc{Into: item{}}).Scan("x"); err == nil {
+		t.Error("expected an error for a non-pointer Doc.Into")
+	}
+}
+
+func ptrTo(v interface{}) interface{} {
+	ptr := reflect.New(reflect.TypeOf(v))
+	ptr.Elem().Set(reflect.ValueOf(v))
+	return ptr.Interface()
+}
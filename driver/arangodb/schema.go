@@ -0,0 +1,240 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+// SchemaStep is a declarative schema change for `Migration.Steps`, ie. a
+// `CollectionSpec`, `IndexSpec` or `ViewSpec`.
+type SchemaStep interface {
+	// Ensure idempotently creates the described object if it doesn't exist yet.
+	Ensure(ctx context.Context, db arango.Database) error
+	// Drop removes the described object if it exists.
+	Drop(ctx context.Context, db arango.Database) error
+	// String describes the object, as reported by `Migrator.DryRun`.
+	String() string
+}
+
+// DatabaseSpec describes a database and its `Collections` for `Driver.Schema`.
+type DatabaseSpec struct {
+	Name    string
+	Options *arango.CreateDatabaseOptions
+	// ensured in order, each incl. its `CollectionSpec.Indexes`
+	Collections []CollectionSpec
+}
+
+// Ensure idempotently creates the database (as the user authenticated via
+// `Driver.Authentication`, which needs access to the `_system` database)
+// and its `Collections` if they don't exist yet.
+func (me *DatabaseSpec) Ensure(ctx context.Context, client arango.Client) (err error) {
+	var db arango.Database
+	var exists bool
+	if exists, err = client.DatabaseExists(ctx, me.Name); err == nil {
+		if exists {
+			db, err = client.Database(ctx, me.Name)
+		} else if db, err = client.CreateDatabase(ctx, me.Name, me.Options); arango.IsConflict(err) {
+			db, err = client.Database(ctx, me.Name) // created concurrently meanwhile
+		}
+	}
+	for i := 0; err == nil && i < len(me.Collections); i++ {
+		err = me.Collections[i].Ensure(ctx, db)
+	}
+	return
+}
+
+// CollectionSpec describes a document or edge collection and its `Indexes`.
+type CollectionSpec struct {
+	Name string
+	// if so, `arango.CollectionTypeEdge`, regardless of `Options.Type`
+	Edge bool
+	// shard count, replication factor, key generator, JSON schema etc.
+	Options *arango.CreateCollectionOptions
+	// their `IndexSpec.Collection` is ignored in favour of `Name`
+	Indexes []IndexSpec
+}
+
+// Ensure implements `SchemaStep` and also ensures all `Indexes`. It never
+// modifies the `Options` of an already-existing collection.
+func (me *CollectionSpec) Ensure(ctx context.Context, db arango.Database) (err error) {
+	var coll arango.Collection
+	var exists bool
+	if exists, err = db.CollectionExists(ctx, me.Name); err == nil {
+		if exists {
+			coll, err = db.Collection(ctx, me.Name)
+		} else {
+			var opts arango.CreateCollectionOptions
+			if me.Options != nil {
+				opts = *me.Options
+			}
+			if me.Edge {
+				opts.Type = arango.CollectionTypeEdge
+			}
+			opts.IsSystem = opts.IsSystem || strings.HasPrefix(me.Name, "_")
+			if coll, err = db.CreateCollection(ctx, me.Name, &opts); arango.IsConflict(err) {
+				coll, err = db.Collection(ctx, me.Name) // created concurrently meanwhile
+			}
+		}
+	}
+	for i := 0; err == nil && i < len(me.Indexes); i++ {
+		err = me.Indexes[i].ensureIn(ctx, coll)
+	}
+	return
+}
+
+// Drop implements `SchemaStep` and drops the collection (incl. its `Indexes`).
+func (me *CollectionSpec) Drop(ctx context.Context, db arango.Database) (err error) {
+	var coll arango.Collection
+	if coll, err = db.Collection(ctx, me.Name); err == nil {
+		err = coll.Remove(ctx)
+	}
+	if arango.IsNotFound(err) {
+		err = nil
+	}
+	return
+}
+
+// String implements `SchemaStep`.
+func (me *CollectionSpec) String() string {
+	if me.Edge {
+		return "edge collection " + me.Name
+	}
+	return "collection " + me.Name
+}
+
+// IndexKind denotes the type of index described by an `IndexSpec`.
+type IndexKind string
+
+const (
+	IndexPersistent IndexKind = IndexKind(arango.PersistentIndex)
+	IndexTTL        IndexKind = IndexKind(arango.TTLIndex)
+	IndexGeo        IndexKind = IndexKind(arango.GeoIndex)
+	IndexFulltext   IndexKind = IndexKind(arango.FullTextIndex)
+)
+
+// IndexSpec describes an index of a collection.
+type IndexSpec struct {
+	Collection string
+	Kind       IndexKind
+	// identifies the index for `Drop` (if empty, it is identified by `Kind` and `Fields` instead)
+	Name string
+	// for `IndexTTL`, only the first one is used
+	Fields []string
+
+	// only for `IndexPersistent`
+	Unique bool
+	// only for `IndexPersistent`
+	Sparse bool
+	// only for `IndexTTL`, in whole seconds
+	ExpireAfter time.Duration
+	// only for `IndexGeo`
+	GeoJSON bool
+	// only for `IndexFulltext`
+	MinLength int
+}
+
+// Ensure implements `SchemaStep`.
+func (me *IndexSpec) Ensure(ctx context.Context, db arango.Database) (err error) {
+	var coll arango.Collection
+	if coll, err = db.Collection(ctx, me.Collection); err == nil {
+		err = me.ensureIn(ctx, coll)
+	}
+	return
+}
+
+func (me *IndexSpec) ensureIn(ctx context.Context, coll arango.Collection) (err error) {
+	switch me.Kind {
+	case IndexPersistent:
+		_, _, err = coll.EnsurePersistentIndex(ctx, me.Fields, &arango.EnsurePersistentIndexOptions{Name: me.Name, Unique: me.Unique, Sparse: me.Sparse})
+	case IndexTTL:
+		var field string
+		if len(me.Fields) > 0 {
+			field = me.Fields[0]
+		}
+		_, _, err = coll.EnsureTTLIndex(ctx, field, int(me.ExpireAfter/time.Second), &arango.EnsureTTLIndexOptions{Name: me.Name})
+	case IndexGeo:
+		_, _, err = coll.EnsureGeoIndex(ctx, me.Fields, &arango.EnsureGeoIndexOptions{Name: me.Name, GeoJSON: me.GeoJSON})
+	case IndexFulltext:
+		_, _, err = coll.EnsureFullTextIndex(ctx, me.Fields, &arango.EnsureFullTextIndexOptions{Name: me.Name, MinLength: me.MinLength})
+	default:
+		err = errors.New("unsupported IndexKind: " + string(me.Kind))
+	}
+	return
+}
+
+// Drop implements `SchemaStep`.
+func (me *IndexSpec) Drop(ctx context.Context, db arango.Database) (err error) {
+	var coll arango.Collection
+	var indexes []arango.Index
+	if coll, err = db.Collection(ctx, me.Collection); err == nil {
+		if indexes, err = coll.Indexes(ctx); err == nil {
+			for _, idx := range indexes {
+				if me.isSpecOf(idx) {
+					if err = idx.Remove(ctx); err != nil {
+						break
+					}
+				}
+			}
+		}
+	}
+	if arango.IsNotFound(err) {
+		err = nil
+	}
+	return
+}
+
+func (me *IndexSpec) isSpecOf(idx arango.Index) bool {
+	if me.Name != "" {
+		return idx.UserName() == me.Name
+	}
+	if fields := idx.Fields(); string(idx.Type()) == string(me.Kind) && len(fields) == len(me.Fields) {
+		for i := range fields {
+			if fields[i] != me.Fields[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// String implements `SchemaStep`.
+func (me *IndexSpec) String() string {
+	return string(me.Kind) + " index " + me.Name + "(" + strings.Join(me.Fields, ", ") + ") on " + me.Collection
+}
+
+// ViewSpec describes an ArangoSearch view.
+type ViewSpec struct {
+	Name       string
+	Properties *arango.ArangoSearchViewProperties
+}
+
+// Ensure implements `SchemaStep`. It never modifies the `Properties` of an already-existing view.
+func (me *ViewSpec) Ensure(ctx context.Context, db arango.Database) (err error) {
+	var exists bool
+	if exists, err = db.ViewExists(ctx, me.Name); err == nil && !exists {
+		if _, err = db.CreateArangoSearchView(ctx, me.Name, me.Properties); arango.IsConflict(err) {
+			err = nil // created concurrently meanwhile
+		}
+	}
+	return
+}
+
+// Drop implements `SchemaStep`.
+func (me *ViewSpec) Drop(ctx context.Context, db arango.Database) (err error) {
+	var view arango.View
+	if view, err = db.View(ctx, me.Name); err == nil {
+		err = view.Remove(ctx)
+	}
+	if arango.IsNotFound(err) {
+		err = nil
+	}
+	return
+}
+
+// String implements `SchemaStep`.
+func (me *ViewSpec) String() string { return "arangosearch view " + me.Name }
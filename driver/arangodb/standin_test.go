@@ -0,0 +1,281 @@
+package usqldrv_arango
+
+import (
+	"context"
+	sqldrv "database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	arangohttp "github.com/arangodb/go-driver/http"
+)
+
+// standIn is a local HTTP stand-in, shared by this package's tests, for the
+// (few) ArangoDB endpoints they exercise: cursors and query management (for
+// query cancellation and batches), JavaScript and stream transactions, and
+// collections and documents (for `Migrator`).
+type standIn struct {
+	*httptest.Server
+	blockQueries bool // if so, cursor creation runs until killed or abandoned
+	// if any for a query text, its cursor responses in order (sans "code", "id" and "hasMore")
+	batches map[string][]map[string]interface{}
+	pending map[string][]map[string]interface{} // cursor ID to remaining batches
+
+	mu             sync.Mutex
+	running        map[string]string // query ID to query text
+	killed         []string          // query IDs
+	deletedCursors []string          // cursor IDs
+	queries        []string          // query texts
+	started        chan string       // query texts
+	transactions   []string          // JavaScript transaction actions
+	txBegins       []string          // stream transaction begin request bodies
+
+	colls map[string]map[string]map[string]interface{} // collection name to document key to document
+	revs  int                                          // last document revision
+}
+
+const standInDB = "/_db/testdb/_api/"
+
+func newStandIn(t *testing.T, blockQueries bool) *standIn {
+	me := &standIn{blockQueries: blockQueries, running: map[string]string{}, colls: map[string]map[string]map[string]interface{}{}, started: make(chan string, 8)}
+	me.Server = httptest.NewServer(http.HandlerFunc(me.serve))
+	t.Cleanup(me.Close)
+	return me
+}
+
+func (me *standIn) reply(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (me *standIn) replyBatch(w http.ResponseWriter, code int, id string, batches []map[string]interface{}) {
+	resp := map[string]interface{}{"code": code, "id": id, "hasMore": len(batches) > 1}
+	for k, v := range batches[0] {
+		resp[k] = v
+	}
+	me.mu.Lock()
+	if me.pending == nil {
+		me.pending = map[string][]map[string]interface{}{}
+	}
+	me.pending[id] = batches[1:]
+	me.mu.Unlock()
+	me.reply(w, code, resp)
+}
+
+func (me *standIn) replyErr(w http.ResponseWriter, code int, errorNum int) {
+	me.reply(w, code, map[string]interface{}{"code": code, "error": true, "errorNum": errorNum, "errorMessage": "stand-in error " + strconv.Itoa(errorNum)})
+}
+
+func (me *standIn) serve(w http.ResponseWriter, r *http.Request) {
+	api := strings.TrimPrefix(r.URL.Path, standInDB)
+	switch {
+	case r.Method == "GET" && api == "database/current":
+		me.reply(w, 200, map[string]interface{}{"code": 200, "result": map[string]interface{}{"name": "testdb", "id": "1"}})
+
+	case r.Method == "POST" && api == "cursor":
+		var req struct {
+			Query    string                 `json:"query"`
+			BindVars map[string]interface{} `json:"bindVars"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		me.mu.Lock()
+		me.queries = append(me.queries, req.Query)
+		batches := me.batches[req.Query]
+		if coll, ok := req.BindVars["@coll"].(string); ok && len(batches) == 0 { // a collection scan, as by `Migrator`
+			docs := []interface{}{}
+			for key, doc := range me.colls[coll] {
+				if key != req.BindVars["lock"] {
+					docs = append(docs, doc)
+				}
+			}
+			me.mu.Unlock()
+			me.reply(w, 201, map[string]interface{}{"code": 201, "hasMore": false, "result": docs})
+			return
+		}
+		me.mu.Unlock()
+		if len(batches) > 0 {
+			me.replyBatch(w, 201, "c"+strconv.Itoa(len(me.queries)), batches)
+			return
+		}
+		if !me.blockQueries {
+			me.reply(w, 201, map[string]interface{}{"code": 201, "id": "123", "hasMore": true, "result": []interface{}{map[string]interface{}{"_key": "a"}}})
+			return
+		}
+		me.mu.Lock()
+		me.running["q1"] = req.Query
+		me.mu.Unlock()
+		me.started <- req.Query
+		<-r.Context().Done()
+
+	case r.Method == "POST" && api == "transaction/begin":
+		body, _ := io.ReadAll(r.Body)
+		me.mu.Lock()
+		me.txBegins = append(me.txBegins, string(body))
+		me.mu.Unlock()
+		me.reply(w, 201, map[string]interface{}{"code": 201, "result": map[string]interface{}{"id": "t1", "status": "running"}})
+
+	case (r.Method == "PUT" || r.Method == "DELETE") && api == "transaction/t1":
+		status := map[string]string{"PUT": "committed", "DELETE": "aborted"}[r.Method]
+		me.reply(w, 200, map[string]interface{}{"code": 200, "result": map[string]interface{}{"id": "t1", "status": status}})
+
+	case r.Method == "POST" && api == "transaction":
+		var req struct {
+			Action string `json:"action"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		me.mu.Lock()
+		me.transactions = append(me.transactions, req.Action)
+		me.mu.Unlock()
+		me.reply(w, 200, map[string]interface{}{"code": 200, "result": nil})
+
+	case (r.Method == "POST" || r.Method == "PUT") && strings.HasPrefix(api, "cursor/c"):
+		id := strings.TrimPrefix(api, "cursor/")
+		me.mu.Lock()
+		batches := me.pending[id]
+		me.mu.Unlock()
+		me.replyBatch(w, 200, id, batches)
+
+	case r.Method == "DELETE" && strings.HasPrefix(api, "cursor/c"):
+		me.mu.Lock()
+		me.deletedCursors = append(me.deletedCursors, strings.TrimPrefix(api, "cursor/"))
+		me.mu.Unlock()
+		me.reply(w, 202, map[string]interface{}{"code": 202})
+
+	case (r.Method == "POST" || r.Method == "PUT") && api == "cursor/123":
+		<-r.Context().Done()
+
+	case r.Method == "DELETE" && api == "cursor/123":
+		me.mu.Lock()
+		me.deletedCursors = append(me.deletedCursors, "123")
+		me.mu.Unlock()
+		me.reply(w, 202, map[string]interface{}{"code": 202, "id": "123"})
+
+	case r.Method == "GET" && strings.HasPrefix(api, "collection/"):
+		name := strings.TrimPrefix(api, "collection/")
+		me.mu.Lock()
+		_, exists := me.colls[name]
+		me.mu.Unlock()
+		if !exists {
+			me.replyErr(w, 404, 1203)
+			return
+		}
+		me.reply(w, 200, map[string]interface{}{"code": 200, "name": name})
+
+	case r.Method == "POST" && api == "collection":
+		var req struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		me.mu.Lock()
+		if me.colls[req.Name] == nil {
+			me.colls[req.Name] = map[string]map[string]interface{}{}
+		}
+		me.mu.Unlock()
+		me.reply(w, 200, map[string]interface{}{"code": 200, "name": req.Name})
+
+	case strings.HasPrefix(api, "document/"):
+		me.serveDocument(w, r, strings.TrimPrefix(api, "document/"))
+
+	case r.Method == "GET" && api == "query/current":
+		var queries []map[string]interface{}
+		me.mu.Lock()
+		for id, query := range me.running {
+			queries = append(queries, map[string]interface{}{"id": id, "query": query})
+		}
+		me.mu.Unlock()
+		me.reply(w, 200, queries)
+
+	case r.Method == "DELETE" && strings.HasPrefix(api, "query/"):
+		me.mu.Lock()
+		id := strings.TrimPrefix(api, "query/")
+		delete(me.running, id)
+		me.killed = append(me.killed, id)
+		me.mu.Unlock()
+		me.reply(w, 200, map[string]interface{}{"code": 200})
+
+	default:
+		me.reply(w, 404, map[string]interface{}{"code": 404, "error": true, "errorNum": 404, "errorMessage": "not in stand-in: " + r.Method + " " + r.URL.Path})
+	}
+}
+
+// serveDocument handles document creation (POST), reads (GET), updates
+// (PATCH) and removals (DELETE), the latter three honouring `If-Match`.
+func (me *standIn) serveDocument(w http.ResponseWriter, r *http.Request, path string) {
+	collname, key := path, ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		collname, key = path[:i], path[i+1:]
+	}
+	var body map[string]interface{}
+	if r.Method == "POST" || r.Method == "PATCH" {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	if r.Method == "POST" {
+		key, _ = body["_key"].(string)
+	}
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	coll := me.colls[collname]
+	doc := coll[key]
+	switch rev := r.Header.Get("If-Match"); {
+	case coll == nil:
+		me.replyErr(w, 404, 1203)
+	case r.Method == "POST" && doc != nil:
+		me.replyErr(w, 409, 1210)
+	case r.Method != "POST" && doc == nil:
+		me.replyErr(w, 404, 1202)
+	case r.Method != "POST" && rev != "" && rev != doc["_rev"]:
+		me.replyErr(w, 412, 1200)
+	case r.Method == "GET":
+		me.reply(w, 200, doc)
+	case r.Method == "DELETE":
+		delete(coll, key)
+		me.reply(w, 202, map[string]interface{}{"_id": doc["_id"], "_key": key, "_rev": doc["_rev"]})
+	default:
+		if doc == nil {
+			doc = map[string]interface{}{}
+		}
+		for k, v := range body {
+			doc[k] = v
+		}
+		me.revs++
+		doc["_id"], doc["_key"], doc["_rev"] = collname+"/"+key, key, "r"+strconv.Itoa(me.revs)
+		coll[key] = doc
+		me.reply(w, 202, map[string]interface{}{"_id": doc["_id"], "_key": key, "_rev": doc["_rev"]})
+	}
+}
+
+func (me *standIn) connector(t *testing.T) sqldrv.Connector {
+	drv := &Driver{Config: arangohttp.ConnectionConfig{Endpoints: []string{me.URL}}}
+	connector, err := drv.OpenConnector("testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return connector
+}
+
+func (me *standIn) conn(t *testing.T) Conn {
+	conn, err := me.connector(t).Connect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn.(Conn)
+}
+
+func (me *standIn) await(t *testing.T, what string, cond func() bool) {
+	for deadline := time.Now().Add(4 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		me.mu.Lock()
+		ok := cond()
+		me.mu.Unlock()
+		if ok {
+			return
+		}
+	}
+	t.Fatal("timed out awaiting: " + what)
+}
@@ -0,0 +1,94 @@
+package usqldrv_arango
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QueryStats are the execution statistics of a `RowsCursor`'s query.
+type QueryStats struct {
+	WritesExecuted  int64
+	WritesIgnored   int64
+	ScannedFull     int64
+	ScannedIndex    int64
+	Filtered        int64
+	HTTPRequests    int64
+	PeakMemoryUsage int64
+	ExecutionTime   time.Duration
+	// only if `QueryOptions.FullCount` in your `QueryWith`
+	FullCount int64
+}
+
+// QueryWarning is a warning issued by ArangoDB for a `RowsCursor`'s query.
+type QueryWarning struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type queryExtra struct {
+	Stats struct {
+		WritesExecuted  int64   `json:"writesExecuted"`
+		WritesIgnored   int64   `json:"writesIgnored"`
+		ScannedFull     int64   `json:"scannedFull"`
+		ScannedIndex    int64   `json:"scannedIndex"`
+		Filtered        int64   `json:"filtered"`
+		HTTPRequests    int64   `json:"httpRequests"`
+		PeakMemoryUsage int64   `json:"peakMemoryUsage"`
+		ExecutionTime   float64 `json:"executionTime"`
+		FullCount       int64   `json:"fullCount"`
+	} `json:"stats"`
+	Warnings []QueryWarning  `json:"warnings"`
+	Profile  json.RawMessage `json:"profile"`
+}
+
+// onRawResponse notes the `extra` attribute of the latest raw cursor response
+// (captured via `arango.WithRawResponse`), if it has one: ArangoDB includes it
+// only in the first (or, for streaming queries, the last) batch.
+func (me *arangoRowsCursor) onRawResponse() {
+	if raw := me.rawResp; len(raw) > 0 {
+		var resp struct {
+			Extra *json.RawMessage `json:"extra"`
+		}
+		if me.rawResp = nil; json.Unmarshal(raw, &resp) == nil && resp.Extra != nil {
+			me.rawExtra, me.extra = *resp.Extra, nil
+		}
+	}
+}
+
+func (me *arangoRowsCursor) queryExtra() *queryExtra {
+	if me.onRawResponse(); me.extra == nil {
+		me.extra = &queryExtra{}
+		if len(me.rawExtra) > 0 {
+			_ = json.Unmarshal(me.rawExtra, me.extra)
+		}
+	}
+	return me.extra
+}
+
+// QueryStats returns the statistics of the query (once they're available,
+// that is for streaming queries only after the last `Next`), also after `Close`.
+func (me *arangoRowsCursor) QueryStats() QueryStats {
+	stats := &me.queryExtra().Stats
+	return QueryStats{
+		WritesExecuted:  stats.WritesExecuted,
+		WritesIgnored:   stats.WritesIgnored,
+		ScannedFull:     stats.ScannedFull,
+		ScannedIndex:    stats.ScannedIndex,
+		Filtered:        stats.Filtered,
+		HTTPRequests:    stats.HTTPRequests,
+		PeakMemoryUsage: stats.PeakMemoryUsage,
+		ExecutionTime:   time.Duration(stats.ExecutionTime * float64(time.Second)),
+		FullCount:       stats.FullCount,
+	}
+}
+
+// QueryWarnings returns the warnings issued for the query, as per `QueryStats`.
+func (me *arangoRowsCursor) QueryWarnings() []QueryWarning {
+	return me.queryExtra().Warnings
+}
+
+// QueryProfile returns the raw JSON profile of the query, as per `QueryStats`
+// and only if `QueryOptions.Profile` in your `QueryWith`.
+func (me *arangoRowsCursor) QueryProfile() json.RawMessage {
+	return me.queryExtra().Profile
+}
@@ -0,0 +1,22 @@
+package usqldrv_arango
+
+import (
+	"testing"
+)
+
+func TestQueryStatsIgnoreResultExtraAttrs(t *testing.T) {
+	rowcur := &arangoRowsCursor{}
+	rowcur.rawResp = []byte(`{"result":[{"_key":"a"}],"hasMore":true,"extra":{"stats":{"scannedFull":42},"warnings":[{"code":1,"message":"w"}]}}`)
+	rowcur.onRawResponse()
+	rowcur.rawResp = []byte(`{"result":[{"_key":"b","extra":{"stats":{"scannedFull":7}}}],"hasMore":false}`)
+	if stats := rowcur.QueryStats(); stats.ScannedFull != 42 {
+		t.Fatalf("expected ScannedFull 42, got %d", stats.ScannedFull)
+	}
+	if warnings := rowcur.QueryWarnings(); len(warnings) != 1 || warnings[0].Message != "w" {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	rowcur.rawResp = []byte(`{"result":[],"hasMore":false,"extra":{"stats":{"scannedFull":43}}}`)
+	if stats := rowcur.QueryStats(); stats.ScannedFull != 43 {
+		t.Fatalf("expected ScannedFull 43 from the later extra, got %d", stats.ScannedFull)
+	}
+}
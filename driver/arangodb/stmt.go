@@ -0,0 +1,61 @@
+package usqldrv_arango
+
+import (
+	"context"
+	sqldrv "database/sql/driver"
+	"errors"
+)
+
+type arangoStmt struct {
+	conn     *arangoConn
+	query    string
+	bindVars []string
+	numInput int
+}
+
+// PrepareContext implements `sqldrv.ConnPrepareContext`: it has the server parse
+// (but not run) `query` once, failing for invalid AQL, and returns a `sqldrv.Stmt`
+// that knows its bind parameters and delegates to `Conn.ExecContext` and
+// `Conn.QueryContext`. If `ctx` is from `Transact`, `query` isn't validated.
+func (me *arangoConn) PrepareContext(ctx context.Context, query string) (stmt sqldrv.Stmt, err error) {
+	self := arangoStmt{conn: me, query: query, numInput: -1}
+	if tctx, _ := ctx.Value(ctxKeyTransact).(*transactCtx); tctx == nil {
+		body := struct {
+			Query string `json:"query"`
+		}{query}
+		if _, err = me.do(me.txCtx(ctx), "POST", "_api/query", &body, 200, "bindVars", &self.bindVars); err == nil {
+			self.numInput = len(self.bindVars)
+		}
+	}
+	if err = me.errFrom(err); err == nil {
+		stmt = &self
+	}
+	return
+}
+
+// Close implements `sqldrv.Stmt` and is a no-op.
+func (*arangoStmt) Close() error { return nil }
+
+// NumInput implements `sqldrv.Stmt` and returns the number of bind parameters
+// found by the server, or -1 for JavaScript transactions.
+func (me *arangoStmt) NumInput() int { return me.numInput }
+
+// Exec implements `sqldrv.Stmt`, but always fails due to being deprecated in favour of `ExecContext`.
+func (*arangoStmt) Exec([]sqldrv.Value) (sqldrv.Result, error) {
+	return nil, errors.New("bug: `Exec` should never be called since this `database/sql/driver.Stmt` also implements `StmtExecContext`")
+}
+
+// Query implements `sqldrv.Stmt`, but always fails due to being deprecated in favour of `QueryContext`.
+func (*arangoStmt) Query([]sqldrv.Value) (sqldrv.Rows, error) {
+	return nil, errors.New("bug: `Query` should never be called since this `database/sql/driver.Stmt` also implements `StmtQueryContext`")
+}
+
+// ExecContext implements `sqldrv.StmtExecContext`
+func (me *arangoStmt) ExecContext(ctx context.Context, args []sqldrv.NamedValue) (sqldrv.Result, error) {
+	return me.conn.ExecContext(ctx, me.query, args)
+}
+
+// QueryContext implements `sqldrv.StmtQueryContext`
+func (me *arangoStmt) QueryContext(ctx context.Context, args []sqldrv.NamedValue) (sqldrv.Rows, error) {
+	return me.conn.QueryContext(ctx, me.query, args)
+}
@@ -0,0 +1,107 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"database/sql"
+	sqldrv "database/sql/driver"
+	"errors"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+type streamTxCtx struct {
+	context.Context
+	Collections arango.TransactionCollections
+	Options     *arango.BeginTransactionOptions
+}
+
+// StreamTx returns a `context.Context` from `ctx` that can be passed to
+// `sql.DB.BeginTx` to declare up-front the `collections` to be read, written
+// or locked exclusively by the stream transaction. Its `transactionOptions`
+// (lock timeout, `waitForSync`, `maxTransactionSize` etc.) may be `nil`.
+func StreamTx(ctx context.Context, collections arango.TransactionCollections, transactionOptions *arango.BeginTransactionOptions) context.Context {
+	return &streamTxCtx{Context: ctx, Collections: collections, Options: transactionOptions}
+}
+
+type arangoTx struct {
+	conn *arangoConn
+	id   arango.TransactionID
+}
+
+// BeginTx implements `sqldrv.ConnBeginTx` by beginning an ArangoDB "stream
+// transaction", through which all subsequent `QueryContext` / `ExecContext`
+// calls on this `Conn` are then routed until its `Commit` or `Rollback`.
+//
+// ArangoDB provides snapshot isolation, so any `opts.Isolation` stronger than
+// `sql.LevelSnapshot` is rejected. If `opts.ReadOnly`, the transaction may
+// read (but not write) all collections it touches. Collections to be written
+// must be declared by passing a `StreamTx` context.
+func (me *arangoConn) BeginTx(ctx context.Context, opts sqldrv.TxOptions) (tx sqldrv.Tx, err error) {
+	if me.txID != "" {
+		err = errors.New("cannot begin a new transaction while the previous one (" + string(me.txID) + ") is still open")
+	} else if isolation := sql.IsolationLevel(opts.Isolation); isolation > sql.LevelSnapshot {
+		err = errors.New("unsupported isolation level: " + isolation.String())
+	} else {
+		var cols arango.TransactionCollections
+		var txopts arango.BeginTransactionOptions
+		if sctx, _ := ctx.(*streamTxCtx); sctx != nil {
+			if cols = sctx.Collections; sctx.Options != nil {
+				txopts = *sctx.Options
+			}
+		}
+		if txopts.AllowImplicit = txopts.AllowImplicit || opts.ReadOnly; opts.ReadOnly && (len(cols.Write) > 0 || len(cols.Exclusive) > 0) {
+			err = errors.New("a read-only transaction cannot declare collections for writing")
+		} else if me.txID, err = me.beginTx(ctx, cols, &txopts); err == nil {
+			tx = &arangoTx{conn: me, id: me.txID}
+		} else {
+			me.txID, err = "", me.errFrom(err)
+		}
+	}
+	return
+}
+
+type beginTxRequest struct {
+	WaitForSync        bool                          `json:"waitForSync,omitempty"`
+	AllowImplicit      bool                          `json:"allowImplicit,omitempty"`
+	LockTimeout        float64                       `json:"lockTimeout,omitempty"`
+	MaxTransactionSize uint64                        `json:"maxTransactionSize,omitempty"`
+	Collections        arango.TransactionCollections `json:"collections,omitempty"`
+}
+
+// beginTx begins a stream transaction as per `arango.Database.BeginTransaction`,
+// except that it also sends the `opts.MaxTransactionSize` that the former drops.
+func (me *arangoConn) beginTx(ctx context.Context, cols arango.TransactionCollections, opts *arango.BeginTransactionOptions) (id arango.TransactionID, err error) {
+	req := beginTxRequest{WaitForSync: opts.WaitForSync, AllowImplicit: opts.AllowImplicit, LockTimeout: opts.LockTimeout.Seconds(),
+		MaxTransactionSize: opts.MaxTransactionSize, Collections: cols}
+	var result struct {
+		ID arango.TransactionID `json:"id"`
+	}
+	if _, err = me.do(ctx, "POST", "_api/transaction/begin", &req, 201, "result", &result); err == nil {
+		id = result.ID
+	}
+	return
+}
+
+// Commit implements `sqldrv.Tx`
+func (me *arangoTx) Commit() error {
+	return me.end(me.conn.CommitTransaction(context.Background(), me.id, nil))
+}
+
+// Rollback implements `sqldrv.Tx`
+func (me *arangoTx) Rollback() error {
+	return me.end(me.conn.AbortTransaction(context.Background(), me.id, nil))
+}
+
+func (me *arangoTx) end(err error) error {
+	if me.conn.txID == me.id {
+		me.conn.txID = ""
+	}
+	return me.conn.errFrom(err)
+}
+
+func (me *arangoConn) txCtx(ctx context.Context) context.Context {
+	if me.txID != "" {
+		ctx = arango.WithTransactionID(ctx, me.txID)
+	}
+	return ctx
+}
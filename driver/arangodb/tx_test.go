@@ -0,0 +1,38 @@
+package usqldrv_arango
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	arango "github.com/arangodb/go-driver"
+)
+
+func TestStreamTxBeginRequest(t *testing.T) {
+	srv := newStandIn(t, false)
+	db := sql.OpenDB(srv.connector(t))
+	defer db.Close()
+	ctx := StreamTx(context.Background(), arango.TransactionCollections{Write: []string{"users"}},
+		&arango.BeginTransactionOptions{LockTimeout: 5 * time.Second, MaxTransactionSize: 1234})
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.txBegins) != 1 {
+		t.Fatalf("expected one begin request, got: %q", srv.txBegins)
+	}
+	var body map[string]interface{}
+	if err = json.Unmarshal([]byte(srv.txBegins[0]), &body); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"lockTimeout": 5.0, "maxTransactionSize": 1234.0,
+		"collections": map[string]interface{}{"write": []interface{}{"users"}}}; !reflect.DeepEqual(body, want) {
+		t.Fatalf("begin request body:\n got: %v\nwant: %v", body, want)
+	}
+}
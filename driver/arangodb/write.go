@@ -0,0 +1,158 @@
+package usqldrv_arango
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// WriteReturn denotes what `Update`, `Replace`, `Remove` and `Upsert` queries `RETURN` per written document.
+type WriteReturn int
+
+const (
+	// RETURN nothing
+	WriteReturnNothing WriteReturn = iota
+	// RETURN the `_key`, `_id` and `_rev` (of `NEW`, or for `Remove`s `OLD`),
+	// so that `Conn.ExecContext`'s `Result.LastInsertId` can pick them up
+	WriteReturnMeta
+	// RETURN OLD
+	WriteReturnOld
+	// RETURN NEW (not supported for `Remove`s, which then return an `error`)
+	WriteReturnNew
+)
+
+// WriteOptions are the AQL `OPTIONS` and `RETURN` choice for `Update`,
+// `Replace`, `Remove` and `Upsert` queries. The zero value implies ArangoDB's
+// defaults, so options defaulting to `true` on the server are negated here.
+type WriteOptions struct {
+	// keepNull: false (only for `Update` and `Upsert`)
+	DiscardNulls bool
+	// mergeObjects: false (only for `Update` and `Upsert`)
+	ReplaceObjects bool
+	// ignoreRevs: false
+	CheckRevs    bool
+	IgnoreErrors bool
+	WaitForSync  bool
+	Return       WriteReturn
+}
+
+func (me *WriteOptions) aql(isPatch bool, isRemove bool) (opts string, err error) {
+	if me != nil {
+		var aqlopts []string
+		if isPatch && me.DiscardNulls {
+			aqlopts = append(aqlopts, "keepNull: false")
+		}
+		if isPatch && me.ReplaceObjects {
+			aqlopts = append(aqlopts, "mergeObjects: false")
+		}
+		if me.CheckRevs {
+			aqlopts = append(aqlopts, "ignoreRevs: false")
+		}
+		if me.IgnoreErrors {
+			aqlopts = append(aqlopts, "ignoreErrors: true")
+		}
+		if me.WaitForSync {
+			aqlopts = append(aqlopts, "waitForSync: true")
+		}
+		opts = aqlOptions(aqlopts)
+
+		doc := "NEW"
+		if isRemove {
+			doc = "OLD"
+		}
+		switch me.Return {
+		case WriteReturnMeta:
+			opts += " RETURN { _key: " + doc + "._key, _id: " + doc + "._id, _rev: " + doc + "._rev }"
+		case WriteReturnOld:
+			opts += " RETURN OLD"
+		case WriteReturnNew:
+			if opts += " RETURN NEW"; isRemove {
+				err = errors.New("WriteReturnNew is not supported for Remove, as there is no NEW document")
+			}
+		}
+	}
+	return
+}
+
+// writeTarget returns the AQL prefix and document expression addressing the
+// document(s) in `@@coll` by `keyOrExample`: if it's a `string`, the single
+// document of that `_key`, otherwise all documents whose top-level attributes
+// equal those of its JSON object form (one index-friendly `FILTER` per attribute),
+// which must not be empty (so as not to accidentally address all documents).
+func writeTarget(keyOrExample interface{}, args []sql.NamedArg) (prefix string, doc string, _ []sql.NamedArg, err error) {
+	if key, ok := keyOrExample.(string); ok {
+		return "", "@key", append(args, sql.Named("key", key)), nil
+	}
+	var data []byte
+	var example map[string]json.RawMessage
+	if data, err = json.Marshal(keyOrExample); err == nil {
+		err = json.Unmarshal(data, &example)
+	}
+	if err != nil || example == nil {
+		return "", "", nil, errors.New("keyOrExample must be a string or marshal to a JSON object, got: " + string(data))
+	}
+	if len(example) == 0 {
+		return "", "", nil, errors.New("keyOrExample must not marshal to an empty JSON object, as that would address all documents")
+	}
+	attrs := make([]string, 0, len(example))
+	for attr := range example {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	prefix = "FOR d IN @@coll "
+	for i, attr := range attrs {
+		n := strconv.Itoa(i + 1)
+		prefix += "FILTER d.@attr" + n + " == @val" + n + " "
+		args = append(args, sql.Named("attr"+n, attr), sql.Named("val"+n, example[attr]))
+	}
+	return prefix, "d", args, nil
+}
+
+// Update constructs a `query` (to merge `patch` into the document(s) in `coll`
+// addressed by `keyOrExample`, see `Replace`) and its `args`, both to be passed
+// to `Conn.ExecContext` or `Conn.QueryContext` (see `Args`).
+func Update(coll string, keyOrExample interface{}, patch interface{}, options *WriteOptions) (query string, args []sql.NamedArg, err error) {
+	var prefix, doc, opts string
+	if prefix, doc, args, err = writeTarget(keyOrExample, []sql.NamedArg{sql.Named("coll", Collection(coll)), sql.Named("patch", patch)}); err == nil {
+		opts, err = options.aql(true, false)
+		query = prefix + "UPDATE " + doc + " WITH @patch IN @@coll" + opts
+	}
+	return
+}
+
+// Replace constructs a `query` (to replace with `doc` the document(s) in
+// `coll` addressed by `keyOrExample`: if it's a `string`, the single document
+// of that `_key`, otherwise all documents whose top-level attributes equal
+// those of `keyOrExample`) and its `args`, both to be passed to
+// `Conn.ExecContext` or `Conn.QueryContext` (see `Args`).
+func Replace(coll string, keyOrExample interface{}, doc interface{}, options *WriteOptions) (query string, args []sql.NamedArg, err error) {
+	var prefix, target, opts string
+	if prefix, target, args, err = writeTarget(keyOrExample, []sql.NamedArg{sql.Named("coll", Collection(coll)), sql.Named("doc", doc)}); err == nil {
+		opts, err = options.aql(false, false)
+		query = prefix + "REPLACE " + target + " WITH @doc IN @@coll" + opts
+	}
+	return
+}
+
+// Remove constructs a `query` (to remove from `coll` the document(s) addressed
+// by `keyOrExample`, see `Replace`) and its `args`, both to be passed to
+// `Conn.ExecContext` or `Conn.QueryContext` (see `Args`).
+func Remove(coll string, keyOrExample interface{}, options *WriteOptions) (query string, args []sql.NamedArg, err error) {
+	var prefix, target, opts string
+	if prefix, target, args, err = writeTarget(keyOrExample, []sql.NamedArg{sql.Named("coll", Collection(coll))}); err == nil {
+		opts, err = options.aql(false, true)
+		query = prefix + "REMOVE " + target + " IN @@coll" + opts
+	}
+	return
+}
+
+// Upsert constructs a `query` (to either merge `patch` into the first document
+// in `coll` matching `example`, or if there is none, to insert `doc`) and its
+// `args`, both to be passed to `Conn.ExecContext` or `Conn.QueryContext` (see `Args`).
+func Upsert(coll string, example interface{}, doc interface{}, patch interface{}, options *WriteOptions) (query string, args []sql.NamedArg) {
+	opts, _ := options.aql(true, false)
+	return "UPSERT @example INSERT @doc UPDATE @patch IN @@coll" + opts, []sql.NamedArg{
+		sql.Named("coll", Collection(coll)), sql.Named("example", example), sql.Named("doc", doc), sql.Named("patch", patch)}
+}
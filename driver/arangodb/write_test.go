@@ -0,0 +1,110 @@
+package usqldrv_arango
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWriteBuild(t *testing.T) {
+	type example struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	patch := map[string]interface{}{"seen": true}
+	for _, test := range []struct {
+		name  string
+		build func() (string, []sql.NamedArg, error)
+		query string
+		args  []sql.NamedArg
+		err   bool
+	}{
+		{
+			name:  "update-by-key",
+			build: func() (string, []sql.NamedArg, error) { return Update("users", "k1", patch, nil) },
+			query: "UPDATE @key WITH @patch IN @@coll",
+			args:  []sql.NamedArg{sql.Named("coll", Collection("users")), sql.Named("patch", patch), sql.Named("key", "k1")},
+		},
+		{
+			name: "update-by-example-with-options",
+			build: func() (string, []sql.NamedArg, error) {
+				return Update("users", example{Name: "x", Age: 3}, patch, &WriteOptions{DiscardNulls: true, ReplaceObjects: true, Return: WriteReturnMeta})
+			},
+			query: "FOR d IN @@coll FILTER d.@attr1 == @val1 FILTER d.@attr2 == @val2 UPDATE d WITH @patch IN @@coll" +
+				" OPTIONS { keepNull: false, mergeObjects: false } RETURN { _key: NEW._key, _id: NEW._id, _rev: NEW._rev }",
+			args: []sql.NamedArg{sql.Named("coll", Collection("users")), sql.Named("patch", patch),
+				sql.Named("attr1", "age"), sql.Named("val1", json.RawMessage("3")), sql.Named("attr2", "name"), sql.Named("val2", json.RawMessage(`"x"`))},
+		},
+		{
+			name: "replace-by-key",
+			build: func() (string, []sql.NamedArg, error) {
+				return Replace("users", "k1", patch, &WriteOptions{DiscardNulls: true, CheckRevs: true, WaitForSync: true, Return: WriteReturnOld})
+			},
+			query: "REPLACE @key WITH @doc IN @@coll OPTIONS { ignoreRevs: false, waitForSync: true } RETURN OLD",
+			args:  []sql.NamedArg{sql.Named("coll", Collection("users")), sql.Named("doc", patch), sql.Named("key", "k1")},
+		},
+		{
+			name: "remove-by-example",
+			build: func() (string, []sql.NamedArg, error) {
+				return Remove("users", map[string]int{"age": 3}, &WriteOptions{IgnoreErrors: true, Return: WriteReturnMeta})
+			},
+			query: "FOR d IN @@coll FILTER d.@attr1 == @val1 REMOVE d IN @@coll OPTIONS { ignoreErrors: true } RETURN { _key: OLD._key, _id: OLD._id, _rev: OLD._rev }",
+			args:  []sql.NamedArg{sql.Named("coll", Collection("users")), sql.Named("attr1", "age"), sql.Named("val1", json.RawMessage("3"))},
+		},
+		{
+			name:  "remove-by-empty-example",
+			build: func() (string, []sql.NamedArg, error) { return Remove("users", map[string]int{}, nil) },
+			err:   true,
+		},
+		{
+			name: "update-by-omitted-example",
+			build: func() (string, []sql.NamedArg, error) {
+				return Update("users", struct {
+					Name string `json:"name,omitempty"`
+				}{}, patch, nil)
+			},
+			err: true,
+		},
+		{
+			name: "remove-return-new",
+			build: func() (string, []sql.NamedArg, error) {
+				return Remove("users", "k1", &WriteOptions{Return: WriteReturnNew})
+			},
+			err: true,
+		},
+		{
+			name:  "non-object-example",
+			build: func() (string, []sql.NamedArg, error) { return Remove("users", 42, nil) },
+			err:   true,
+		},
+		{
+			name: "upsert",
+			build: func() (string, []sql.NamedArg, error) {
+				query, args := Upsert("users", map[string]int{"age": 3}, patch, patch, &WriteOptions{Return: WriteReturnNew})
+				return query, args, nil
+			},
+			query: "UPSERT @example INSERT @doc UPDATE @patch IN @@coll RETURN NEW",
+			args: []sql.NamedArg{sql.Named("coll", Collection("users")), sql.Named("example", map[string]int{"age": 3}),
+				sql.Named("doc", patch), sql.Named("patch", patch)},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			query, args, err := test.build()
+			if test.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if query != test.query {
+				t.Errorf("query:\n got: %s\nwant: %s", query, test.query)
+			}
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("args:\n got: %v\nwant: %v", args, test.args)
+			}
+		})
+	}
+}